@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -35,6 +36,8 @@ type AppConfig struct {
 	Environment string `mapstructure:"environment"`
 	Port        string `mapstructure:"port"`
 	Debug       bool   `mapstructure:"debug"`
+	// SchedulerInterval is how often goods activation windows are checked
+	SchedulerInterval time.Duration `mapstructure:"scheduler_interval"`
 }
 
 type APIServer struct {
@@ -0,0 +1,264 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paxaf/HezzlTest/config"
+	"github.com/paxaf/HezzlTest/internal/entity"
+	clickHouse "github.com/paxaf/HezzlTest/internal/repository/clickhouse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	queryInsertTestEvent = `INSERT INTO logs.events (timestamp, action, entity, entity_id, project_id, name, priority)
+	VALUES (?, ?, ?, ?, ?, ?, ?)`
+	queryInsertGoodsEvent = `INSERT INTO logs.events (event_time, timestamp, action, entity, entity_id, project_id,
+		from_project_id, name, description)
+	VALUES (?, ?, ?, 'good', ?, ?, ?, ?, ?)`
+	queryInsertUpdateEvent = `INSERT INTO logs.events (timestamp, action, entity, entity_id, project_id, name, changed_fields)
+	VALUES (?, 'update', 'good', ?, 1, ?, ?)`
+)
+
+type ClickHouseSuite struct {
+	suite.Suite
+	chContainer testcontainers.Container
+	repo        *clickHouse.ClickHouse
+}
+
+func TestClickHouse(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests")
+	}
+	suite.Run(t, new(ClickHouseSuite))
+}
+
+func (s *ClickHouseSuite) SetupSuite() {
+	ctx := context.Background()
+
+	req := testcontainers.ContainerRequest{
+		Image:        "clickhouse/clickhouse-server:23.10",
+		ExposedPorts: []string{"9000/tcp"},
+		Env: map[string]string{
+			"CLICKHOUSE_USER":     "testuser",
+			"CLICKHOUSE_PASSWORD": "testpass",
+		},
+		WaitingFor: wait.ForListeningPort("9000/tcp").WithStartupTimeout(60 * time.Second),
+	}
+
+	chContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	require.NoError(s.T(), err, "Failed to start ClickHouse container")
+	s.chContainer = chContainer
+
+	endpoint, err := chContainer.PortEndpoint(ctx, "9000/tcp", "")
+	require.NoError(s.T(), err, "Failed to get ClickHouse endpoint")
+
+	s.repo, err = clickHouse.NewClickHouse(config.Clickhouse{
+		Address:  endpoint,
+		Database: "default",
+		Username: "testuser",
+		Password: "testpass",
+	})
+	require.NoError(s.T(), err, "Failed to connect to ClickHouse")
+
+	err = applyClickHouseMigrations(ctx, s.repo, "../migrations/clickhouse")
+	require.NoError(s.T(), err, "Failed to apply migrations")
+}
+
+func (s *ClickHouseSuite) TearDownTest() {
+	_ = s.repo.Conn.Exec(context.Background(), "TRUNCATE TABLE logs.events")
+}
+
+func (s *ClickHouseSuite) TearDownSuite() {
+	if s.repo != nil {
+		_ = s.repo.Close()
+	}
+	if s.chContainer != nil {
+		_ = s.chContainer.Terminate(context.Background())
+	}
+}
+
+// applyClickHouseMigrations runs the init scripts in name order, the native
+// protocol takes one statement at a time.
+func applyClickHouseMigrations(ctx context.Context, ch *clickHouse.ClickHouse, dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		for _, stmt := range strings.Split(string(data), ";") {
+			if stmt = strings.TrimSpace(stmt); stmt == "" {
+				continue
+			}
+			if err = ch.Conn.Exec(ctx, stmt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ClickHouseSuite) TestAuditEvents() {
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(time.Second)
+	events := []struct {
+		action    entity.EventAction
+		entity    string
+		id        int32
+		projectId int32
+	}{
+		{entity.Create, entity.AuditProject, 1, 1},
+		{entity.Create, entity.AuditGoods, 1, 1},
+		{entity.Update, entity.AuditGoods, 1, 1},
+		{entity.Create, entity.AuditGoods, 2, 2},
+	}
+	for i, e := range events {
+		err := s.repo.Conn.Exec(ctx, queryInsertTestEvent,
+			start.Add(time.Duration(i)*time.Second), string(e.action), e.entity, e.id, e.projectId, "Item", int32(i+1))
+		require.NoError(s.T(), err)
+	}
+
+	id := 1
+	history, err := s.repo.GetEvents(ctx, entity.AuditFilter{Entity: entity.AuditGoods, EntityId: &id}, entity.Page{Limit: 1})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), history.Meta.Total, 2)
+	require.Len(s.T(), history.Events, 1)
+	assert.Equal(s.T(), history.Events[0].Action, entity.Update)
+	assert.Equal(s.T(), *history.Events[0].Priority, 3)
+	require.NotEmpty(s.T(), history.Meta.NextCursor)
+
+	after, err := entity.DecodeCursor(history.Meta.NextCursor)
+	require.NoError(s.T(), err)
+	history, err = s.repo.GetEvents(ctx, entity.AuditFilter{Entity: entity.AuditGoods, EntityId: &id}, entity.Page{Limit: 1, After: after})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), history.Events, 1)
+	assert.Equal(s.T(), history.Events[0].Action, entity.Create)
+	assert.Empty(s.T(), history.Meta.NextCursor)
+
+	to := start.Add(3 * time.Second)
+	audit, err := s.repo.GetEvents(ctx, entity.AuditFilter{Action: entity.Create, ProjectId: &id, To: &to}, firstPage)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), audit.Events, 2)
+}
+
+func (s *ClickHouseSuite) TestAuditEventsKeyset() {
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(time.Second)
+	for i := 1; i <= 3; i++ {
+		err := s.repo.Conn.Exec(ctx, queryInsertTestEvent,
+			start, string(entity.Update), entity.AuditGoods, int32(7), int32(1), "Item", int32(i))
+		require.NoError(s.T(), err)
+	}
+
+	id := 7
+	filter := entity.AuditFilter{Entity: entity.AuditGoods, EntityId: &id}
+	seen := map[int]bool{}
+	page := entity.Page{Limit: 1}
+	for i := 0; ; i++ {
+		history, err := s.repo.GetEvents(ctx, filter, page)
+		require.NoError(s.T(), err)
+		require.Len(s.T(), history.Events, 1)
+		seen[*history.Events[0].Priority] = true
+		if i == 0 {
+			err = s.repo.Conn.Exec(ctx, queryInsertTestEvent,
+				start.Add(time.Second), string(entity.Update), entity.AuditGoods, int32(7), int32(1), "Item", int32(4))
+			require.NoError(s.T(), err)
+		}
+		if history.Meta.NextCursor == "" {
+			break
+		}
+		page.After, err = entity.DecodeCursor(history.Meta.NextCursor)
+		require.NoError(s.T(), err)
+	}
+	assert.Equal(s.T(), map[int]bool{1: true, 2: true, 3: true}, seen)
+}
+
+func (s *ClickHouseSuite) TestAuditChangedFields() {
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(time.Second)
+	changes := [][]string{{"description"}, {"currency", "price"}, {"description", "name"}}
+	for i, fields := range changes {
+		err := s.repo.Conn.Exec(ctx, queryInsertUpdateEvent, start.Add(time.Duration(i)*time.Second), int32(1), "Item", fields)
+		require.NoError(s.T(), err)
+	}
+
+	audit, err := s.repo.GetEvents(ctx, entity.AuditFilter{Field: "description"}, firstPage)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), audit.Events, 2)
+	assert.Equal(s.T(), audit.Events[0].ChangedFields, []string{"description", "name"})
+	assert.Equal(s.T(), audit.Events[1].ChangedFields, []string{"description"})
+
+	audit, err = s.repo.GetEvents(ctx, entity.AuditFilter{Field: "tags"}, firstPage)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), audit.Events)
+}
+
+func (s *ClickHouseSuite) TestGoodsAsOf() {
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(time.Second)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	err := s.repo.Conn.Exec(ctx, queryInsertTestEvent, at(0), string(entity.Create), entity.AuditProject, int32(1), int32(1), "Project", nil)
+	require.NoError(s.T(), err)
+	description := func(d string) *string { return &d }
+	events := []struct {
+		ms          int
+		action      entity.EventAction
+		id          int32
+		projectId   int32
+		fromProject *int32
+		name        string
+		description *string
+	}{
+		{100, entity.Create, 1, 1, nil, "First item", description("first")},
+		{100, entity.Create, 3, 1, nil, "Moved item", description("")},
+		{200, entity.Update, 2, 1, nil, "Legacy item", description("legacy")},
+		{200, entity.Move, 3, 2, new(int32), "Moved item", nil},
+		{300, entity.Update, 1, 1, nil, "", description("second")},
+	}
+	*events[3].fromProject = 1
+	for _, e := range events {
+		err = s.repo.Conn.Exec(ctx, queryInsertGoodsEvent, at(e.ms), at(e.ms), string(e.action), e.id, e.projectId,
+			e.fromProject, e.name, e.description)
+		require.NoError(s.T(), err)
+	}
+
+	res, err := s.repo.GetProjectGoodsAsOf(ctx, 1, at(250), false)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), res.Known)
+	require.Len(s.T(), res.Goods, 2)
+	assert.True(s.T(), res.Goods[0].Known)
+	assert.Equal(s.T(), *res.Goods[0].Description, "first")
+	assert.False(s.T(), res.Goods[1].Known)
+	assert.Equal(s.T(), *res.Goods[1].Description, "legacy")
+
+	res, err = s.repo.GetProjectGoodsAsOf(ctx, 1, at(400), false)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), res.Goods, 2)
+	assert.Equal(s.T(), *res.Goods[0].Name, "First item")
+	assert.Equal(s.T(), *res.Goods[0].Description, "second")
+
+	err = s.repo.Conn.Exec(ctx, queryInsertTestEvent, at(500), string(entity.Delete), entity.AuditProject, int32(1), int32(1), "Project", nil)
+	require.NoError(s.T(), err)
+	_, err = s.repo.GetProjectGoodsAsOf(ctx, 1, at(600), false)
+	require.ErrorIs(s.T(), err, entity.ErrNotFound)
+
+	res, err = s.repo.GetProjectGoodsAsOf(ctx, 3, at(250), false)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), res.Known)
+	assert.Empty(s.T(), res.Goods)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/paxaf/HezzlTest/internal/entity"
 	"github.com/paxaf/HezzlTest/internal/repository/postgres"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -18,6 +19,11 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
+var (
+	firstPage   = entity.Page{Limit: entity.DefaultPageLimit}
+	activeGoods = entity.GoodsFilter{Removed: new(bool)}
+)
+
 type PostgresSuite struct {
 	suite.Suite
 	pgContainer testcontainers.Container
@@ -71,7 +77,9 @@ func (s *PostgresSuite) SetupSuite() {
 }
 
 func (s *PostgresSuite) TearDownTest() {
-	_, _ = s.PgPool.Exec(context.Background(), "TRUNCATE TABLE GOODS RESTART IDENTITY")
+	_, _ = s.PgPool.Exec(context.Background(), "TRUNCATE TABLE GOODS, tags RESTART IDENTITY CASCADE")
+	// archived projects are read-only, the next test writes into them again
+	_, _ = s.PgPool.Exec(context.Background(), "UPDATE projects SET status = 'active' WHERE status = 'archived'")
 }
 
 func (s *PostgresSuite) TearDownSuite() {
@@ -115,21 +123,33 @@ func (s *PostgresSuite) TestWriteGoods() {
 		Description: "New description",
 		Priority:    10,
 	}
-	err = s.repo.UpdateItem(ctx, updItem)
+	prev, err := s.repo.UpdateItem(ctx, updItem)
 	require.NoError(s.T(), err)
+	assert.Equal(s.T(), prev.Name, "First item")
+	assert.Equal(s.T(), prev.Version, 1)
+	assert.Equal(s.T(), updItem.CreatedAt, prev.CreatedAt)
 	getItem, err = s.repo.GetItem(ctx, item.ProjectId)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), getItem.ProjectId, updItem.ProjectId)
 	assert.Equal(s.T(), getItem.Name, updItem.Name)
 	assert.Equal(s.T(), getItem.Priority, updItem.Priority)
-	err = s.repo.DeleteItem(ctx, getItem.Id)
+	deleted, err := s.repo.DeleteItem(ctx, getItem.Id)
 	require.NoError(s.T(), err)
-	err = s.repo.UpdateItem(ctx, updItem)
+	assert.True(s.T(), deleted.Removed)
+	assert.NotNil(s.T(), deleted.RemovedAt)
+	_, err = s.repo.DeleteItem(ctx, getItem.Id)
+	require.ErrorIs(s.T(), err, entity.ErrNotFound)
+	restored, err := s.repo.RestoreItem(ctx, getItem.Id)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), restored.Removed)
+	assert.Nil(s.T(), restored.RemovedAt)
+	updItem.Id = 10
+	_, err = s.repo.UpdateItem(ctx, updItem)
 	require.ErrorAs(s.T(), err, &pgx.ErrNoRows)
-	nilItem, err := s.repo.GetItem(ctx, 1)
+	nilItem, err := s.repo.GetItem(ctx, 10)
 	require.Nil(s.T(), nilItem)
 	require.ErrorAs(s.T(), err, &pgx.ErrNoRows)
-	err = s.repo.DeleteItem(ctx, 10)
+	_, err = s.repo.DeleteItem(ctx, 10)
 	require.ErrorAs(s.T(), err, &pgx.ErrNoRows)
 	item.ProjectId = 2
 	err = s.repo.CreateItem(ctx, item)
@@ -157,14 +177,723 @@ func (s *PostgresSuite) TestReadGoods() {
 		err := s.repo.CreateItem(ctx, val)
 		require.NoError(s.T(), err)
 	}
-	allItems, err := s.repo.GetAllItems(ctx)
+	allItems, err := s.repo.GetAllItems(ctx, activeGoods, firstPage)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), allItems.Goods, 3)
+	namedItems, err := s.repo.GetItemsByName(ctx, "item", false, "", firstPage)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), namedItems.Goods, 3)
+	namedItems, err = s.repo.GetItemsByName(ctx, "three", false, "", firstPage)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), namedItems.Goods, 1)
+	assert.Equal(s.T(), namedItems.Goods[0].Name, goods[2].Name)
+	_, err = s.repo.DeleteItem(ctx, 3)
+	require.NoError(s.T(), err)
+	allItems, err = s.repo.GetAllItems(ctx, activeGoods, firstPage)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), allItems.Goods, 2)
+	allItems, err = s.repo.GetAllItems(ctx, entity.GoodsFilter{}, firstPage)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), allItems.Goods, 3)
+}
+
+func (s *PostgresSuite) TestReprioritizeGoods() {
+	ctx := context.Background()
+	for _, name := range []string{"First item", "Second item", "Three item"} {
+		err := s.repo.CreateItem(ctx, &entity.Goods{ProjectId: 1, Name: name})
+		require.NoError(s.T(), err)
+	}
+	changed, err := s.repo.ReprioritizeItem(ctx, 3, 1)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), changed, 3)
+	assert.Equal(s.T(), changed[0].Item.Id, 3)
+	assert.Equal(s.T(), changed[0].Item.Priority, 1)
+	assert.Equal(s.T(), changed[0].Prev.Priority, 3)
+	for _, change := range changed[1:] {
+		assert.Equal(s.T(), change.Prev.Priority+1, change.Item.Priority)
+		assert.Equal(s.T(), change.Prev.Version+1, change.Item.Version)
+	}
+	first, err := s.repo.GetItem(ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), first.Priority, 2)
+	second, err := s.repo.GetItem(ctx, 2)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), second.Priority, 3)
+	_, err = s.repo.ReprioritizeItem(ctx, 10, 1)
+	require.ErrorIs(s.T(), err, entity.ErrNotFound)
+}
+
+func (s *PostgresSuite) TestReprioritizeGoodsDown() {
+	ctx := context.Background()
+	for _, name := range []string{"First item", "Second item", "Three item", "Four item", "Five item"} {
+		err := s.repo.CreateItem(ctx, &entity.Goods{ProjectId: 1, Name: name})
+		require.NoError(s.T(), err)
+	}
+	changed, err := s.repo.ReprioritizeItem(ctx, 2, 4)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), changed, 3)
+	assert.Equal(s.T(), changed[0].Item.Id, 2)
+	assert.Equal(s.T(), changed[0].Item.Priority, 4)
+	for _, change := range changed[1:] {
+		assert.Equal(s.T(), change.Prev.Priority-1, change.Item.Priority)
+	}
+	changed, err = s.repo.ReprioritizeItem(ctx, 1, 100)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), changed[0].Item.Priority, 5)
+
+	goods, err := s.repo.GetAllItems(ctx, entity.GoodsFilter{}, firstPage)
+	require.NoError(s.T(), err)
+	order := make([]int, 0, len(goods.Goods))
+	for i, item := range goods.Goods {
+		assert.Equal(s.T(), i+1, item.Priority)
+		order = append(order, item.Id)
+	}
+	assert.Equal(s.T(), []int{3, 4, 2, 5, 1}, order)
+	five, err := s.repo.GetItem(ctx, 5)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, five.Version)
+}
+
+func (s *PostgresSuite) TestMoveGoods() {
+	ctx := context.Background()
+	project := &entity.Project{Name: "Target project"}
+	err := s.repo.AddProject(ctx, project)
+	require.NoError(s.T(), err)
+	goods := []*entity.Goods{
+		{ProjectId: 1, Name: "First item"},
+		{ProjectId: 1, Name: "Second item"},
+		{ProjectId: project.Id, Name: "Target item"},
+	}
+	for _, val := range goods {
+		err := s.repo.CreateItem(ctx, val)
+		require.NoError(s.T(), err)
+	}
+	res, err := s.repo.MoveItem(ctx, goods[0].Id, project.Id, 1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), res.FromProjectId, 1)
+	assert.Equal(s.T(), res.Item.ProjectId, project.Id)
+	assert.Equal(s.T(), res.Item.Priority, 1)
+	assert.Len(s.T(), res.Shifted, 2)
+	require.Len(s.T(), res.ShiftedPrev, 2)
+	for i, item := range res.Shifted {
+		assert.Equal(s.T(), res.ShiftedPrev[i].Id, item.Id)
+		assert.Equal(s.T(), res.ShiftedPrev[i].Version+1, item.Version)
+	}
+	second, err := s.repo.GetItem(ctx, goods[1].Id)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), second.Priority, 1)
+	target, err := s.repo.GetItem(ctx, goods[2].Id)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), target.Priority, 2)
+	res, err = s.repo.MoveItem(ctx, goods[1].Id, project.Id, 0)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), res.Item.Priority, 3)
+	_, err = s.repo.MoveItem(ctx, goods[1].Id, project.Id+100, 1)
+	require.ErrorIs(s.T(), err, entity.ErrProjectNotFound)
+	_, err = s.repo.MoveItem(ctx, 1000, project.Id, 1)
+	require.ErrorIs(s.T(), err, entity.ErrNotFound)
+}
+
+func (s *PostgresSuite) TestDeleteProject() {
+	ctx := context.Background()
+	project := &entity.Project{Name: "Doomed project"}
+	err := s.repo.AddProject(ctx, project)
+	require.NoError(s.T(), err)
+	for _, name := range []string{"First item", "Second item"} {
+		err := s.repo.CreateItem(ctx, &entity.Goods{ProjectId: project.Id, Name: name})
+		require.NoError(s.T(), err)
+	}
+	_, _, err = s.repo.DeleteProject(ctx, project.Id, false)
+	require.ErrorIs(s.T(), err, entity.ErrProjectNotEmpty)
+	deleted, goods, err := s.repo.DeleteProject(ctx, project.Id, true)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), deleted.Name, project.Name)
+	assert.Len(s.T(), goods, 2)
+	_, _, err = s.repo.DeleteProject(ctx, project.Id, true)
+	require.ErrorIs(s.T(), err, entity.ErrNotFound)
+}
+
+func (s *PostgresSuite) TestCloneProject() {
+	ctx := context.Background()
+	for _, name := range []string{"First item", "Second item", "Three item"} {
+		err := s.repo.CreateItem(ctx, &entity.Goods{ProjectId: 1, Name: name})
+		require.NoError(s.T(), err)
+	}
+	_, err := s.repo.ReprioritizeItem(ctx, 3, 1)
+	require.NoError(s.T(), err)
+	_, err = s.repo.DeleteItem(ctx, 2)
+	require.NoError(s.T(), err)
+	project, goods, err := s.repo.CloneProject(ctx, 1, "Cloned project")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), project.Name, "Cloned project")
+	require.Len(s.T(), goods, 2)
+	byPriority := map[int]string{}
+	for _, item := range goods {
+		assert.Equal(s.T(), item.ProjectId, project.Id)
+		byPriority[item.Priority] = item.Name
+	}
+	assert.Equal(s.T(), byPriority, map[int]string{1: "Three item", 2: "First item"})
+	_, _, err = s.repo.CloneProject(ctx, 1000, "Missing")
+	require.ErrorIs(s.T(), err, entity.ErrNotFound)
+}
+
+func (s *PostgresSuite) TestArchivedProjectIsReadOnly() {
+	ctx := context.Background()
+	item := &entity.Goods{ProjectId: 1, Name: "First item"}
+	err := s.repo.CreateItem(ctx, item)
+	require.NoError(s.T(), err)
+	project, err := s.repo.SetProjectStatus(ctx, 1, entity.ProjectActive, entity.ProjectArchived)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), project.Status, entity.ProjectArchived)
+	_, err = s.repo.SetProjectStatus(ctx, 1, entity.ProjectActive, entity.ProjectPaused)
+	require.ErrorIs(s.T(), err, entity.ErrVersionConflict)
+	archived, err := s.repo.GetProjects(ctx, entity.ProjectFilter{Status: entity.ProjectArchived}, firstPage)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), archived.Project, 1)
+	err = s.repo.CreateItem(ctx, &entity.Goods{ProjectId: 1, Name: "Second item"})
+	require.ErrorIs(s.T(), err, entity.ErrProjectArchived)
+	_, err = s.repo.DeleteItem(ctx, item.Id)
+	require.ErrorIs(s.T(), err, entity.ErrProjectArchived)
+	_, err = s.repo.DeleteItems(ctx, entity.GoodsSelector{Ids: []int{item.Id}})
+	require.ErrorIs(s.T(), err, entity.ErrProjectArchived)
+	stored, err := s.repo.GetItem(ctx, item.Id)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), stored.Removed)
+}
+
+func (s *PostgresSuite) TestGoodsActivationWindow() {
+	ctx := context.Background()
+	now := time.Now()
+	from, to := now.Add(time.Hour), now.Add(3*time.Hour)
+	item := &entity.Goods{ProjectId: 1, Name: "Scheduled item", ActiveFrom: &from, ActiveTo: &to}
+	err := s.repo.CreateItem(ctx, item)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), item.Status, entity.GoodsDraft)
+	changes, err := s.repo.ActivateDueItems(ctx, now)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), changes)
+	changes, err = s.repo.ActivateDueItems(ctx, now.Add(2*time.Hour))
+	require.NoError(s.T(), err)
+	require.Len(s.T(), changes, 1)
+	assert.Equal(s.T(), changes[0].From, entity.GoodsDraft)
+	assert.Equal(s.T(), changes[0].Item.Status, entity.GoodsActive)
+	paused, err := s.repo.SetItemStatus(ctx, item.Id, entity.GoodsActive, entity.GoodsPaused)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), paused.Status, entity.GoodsPaused)
+	_, err = s.repo.SetItemStatus(ctx, item.Id, entity.GoodsActive, entity.GoodsPaused)
+	require.ErrorIs(s.T(), err, entity.ErrVersionConflict)
+	changes, err = s.repo.ExpireDueItems(ctx, now.Add(4*time.Hour))
+	require.NoError(s.T(), err)
+	require.Len(s.T(), changes, 1)
+	assert.Equal(s.T(), changes[0].From, entity.GoodsPaused)
+	assert.Equal(s.T(), changes[0].Item.Status, entity.GoodsExpired)
+}
+
+func (s *PostgresSuite) TestSetItemWindowStatus() {
+	ctx := context.Background()
+	now := time.Now()
+	item := &entity.Goods{ProjectId: 1, Name: "Windowed item"}
+	require.NoError(s.T(), s.repo.CreateItem(ctx, item))
+	require.Equal(s.T(), entity.GoodsActive, item.Status)
+
+	from := now.Add(time.Hour)
+	change, err := s.repo.SetItemWindow(ctx, item.Id, &from, nil, now)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), entity.GoodsActive, change.Prev.Status)
+	assert.Nil(s.T(), change.Prev.ActiveFrom)
+	assert.Equal(s.T(), entity.GoodsDraft, change.Item.Status)
+	changes, err := s.repo.ActivateDueItems(ctx, now.Add(2*time.Hour))
+	require.NoError(s.T(), err)
+	require.Len(s.T(), changes, 1)
+	assert.Equal(s.T(), item.Id, changes[0].Item.Id)
+
+	to := now.Add(-time.Minute)
+	change, err = s.repo.SetItemWindow(ctx, item.Id, nil, &to, now)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), entity.GoodsActive, change.Prev.Status)
+	assert.Equal(s.T(), entity.GoodsExpired, change.Item.Status)
+
+	_, err = s.repo.SetItemWindow(ctx, 1000, nil, nil, now)
+	require.ErrorIs(s.T(), err, entity.ErrNotFound)
+}
+
+func (s *PostgresSuite) TestGoodsTags() {
+	ctx := context.Background()
+	for _, name := range []string{"First item", "Second item"} {
+		err := s.repo.CreateItem(ctx, &entity.Goods{ProjectId: 1, Name: name})
+		require.NoError(s.T(), err)
+	}
+	summer := &entity.Tag{Name: "summer"}
+	err := s.repo.CreateTag(ctx, summer)
+	require.NoError(s.T(), err)
+	err = s.repo.CreateTag(ctx, &entity.Tag{Name: "summer"})
+	require.ErrorIs(s.T(), err, entity.ErrTagExists)
+	item, err := s.repo.SetItemTags(ctx, 1, []string{"clearance", "summer"})
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), item.Prev.Tags)
+	assert.Equal(s.T(), item.Item.Tags, []string{"clearance", "summer"})
+	tagged, err := s.repo.GetAllItems(ctx, entity.GoodsFilter{Tag: "summer"}, firstPage)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), tagged.Goods, 1)
+	assert.Equal(s.T(), tagged.Goods[0].Id, 1)
+	project := &entity.Project{Name: "Archived project"}
+	require.NoError(s.T(), s.repo.AddProject(ctx, project))
+	archived := &entity.Goods{ProjectId: project.Id, Name: "Archived item"}
+	require.NoError(s.T(), s.repo.CreateItem(ctx, archived))
+	change, err := s.repo.SetItemTags(ctx, archived.Id, []string{"summer"})
+	require.NoError(s.T(), err)
+	archived = &change.Item
+	_, err = s.repo.SetProjectStatus(ctx, project.Id, project.Status, entity.ProjectArchived)
+	require.NoError(s.T(), err)
+	summer.Name = "winter"
+	_, err = s.repo.RenameTag(ctx, summer)
+	require.ErrorIs(s.T(), err, entity.ErrProjectArchived)
+	_, err = s.repo.DeleteTag(ctx, summer.Id)
+	require.ErrorIs(s.T(), err, entity.ErrProjectArchived)
+	stored, err := s.repo.GetItem(ctx, archived.Id)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), stored.Tags, []string{"summer"})
+	assert.Equal(s.T(), stored.Version, archived.Version)
+	_, err = s.PgPool.Exec(ctx, "DELETE FROM goods_tags WHERE goods_id = $1", archived.Id)
+	require.NoError(s.T(), err)
+
+	summer.Name = "winter"
+	touched, err := s.repo.RenameTag(ctx, summer)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), touched, 1)
+	assert.Equal(s.T(), touched[0].Prev.Tags, []string{"clearance", "summer"})
+	assert.Equal(s.T(), touched[0].Item.Tags, []string{"clearance", "winter"})
+	touched, err = s.repo.DeleteTag(ctx, summer.Id)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), touched, 1)
+	assert.Equal(s.T(), touched[0].Prev.Tags, []string{"clearance", "winter"})
+	assert.Equal(s.T(), touched[0].Item.Tags, []string{"clearance"})
+	tags, err := s.repo.GetTags(ctx)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), tags, 1)
+}
+
+func (s *PostgresSuite) TestGoodsAttributes() {
+	ctx := context.Background()
+	first := &entity.Goods{ProjectId: 1, Name: "First item", Attributes: map[string]interface{}{"brand": "acme", "size": 42.0}}
+	err := s.repo.CreateItem(ctx, first)
+	require.NoError(s.T(), err)
+	err = s.repo.CreateItem(ctx, &entity.Goods{ProjectId: 1, Name: "Second item"})
+	require.NoError(s.T(), err)
+	filtered, err := s.repo.GetAllItems(ctx, entity.GoodsFilter{Attributes: map[string]string{"brand": "acme", "size": "42"}}, firstPage)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), filtered.Goods, 1)
+	assert.Equal(s.T(), filtered.Goods[0].Id, first.Id)
+	second, err := s.repo.GetItem(ctx, first.Id+1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), second.Attributes, map[string]interface{}{})
+	_, changes, err := s.repo.PatchItem(ctx, first, entity.GoodsPatch{
+		Attributes: map[string]interface{}{"brand": "acme", "size": nil, "color": "red"},
+	})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), changes.Attributes, map[string]interface{}{"size": nil, "color": "red"})
+	assert.Equal(s.T(), first.Attributes, map[string]interface{}{"brand": "acme", "color": "red"})
+}
+
+func (s *PostgresSuite) TestGoodsPrice() {
+	ctx := context.Background()
+	usd, eur := "USD", "EUR"
+	prices := []struct {
+		price    string
+		currency *string
+	}{{"10.10", &usd}, {"20.2", &usd}, {"5", &eur}, {"", nil}}
+	for i, val := range prices {
+		item := &entity.Goods{ProjectId: 1, Name: fmt.Sprintf("Item %d", i), Currency: val.currency}
+		if val.price != "" {
+			price := decimal.RequireFromString(val.price)
+			item.Price = &price
+		}
+		err := s.repo.CreateItem(ctx, item)
+		require.NoError(s.T(), err)
+	}
+	min := decimal.RequireFromString("10.1")
+	filtered, err := s.repo.GetAllItems(ctx, entity.GoodsFilter{PriceMin: &min, Currency: usd}, firstPage)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), filtered.Goods, 2)
+	assert.Equal(s.T(), filtered.Goods[0].Price.String(), "10.1")
+	summary, err := s.repo.GetProjectSummary(ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), summary.Goods, 4)
+	assert.Equal(s.T(), summary.Priced, 3)
+	require.Len(s.T(), summary.Prices, 2)
+	assert.Equal(s.T(), summary.Prices[1].Currency, usd)
+	assert.Equal(s.T(), summary.Prices[1].Avg.String(), "15.15")
+	item := &entity.Goods{Id: filtered.Goods[0].Id}
+	_, changes, err := s.repo.PatchItem(ctx, item, entity.GoodsPatch{ClearPrice: true})
+	require.NoError(s.T(), err)
+	assert.True(s.T(), changes.ClearPrice)
+	assert.Nil(s.T(), item.Price)
+	assert.Nil(s.T(), item.Currency)
+	_, err = s.repo.GetProjectSummary(ctx, 100)
+	require.ErrorIs(s.T(), err, entity.ErrNotFound)
+}
+
+func (s *PostgresSuite) TestGoodsRevisions() {
+	ctx := context.Background()
+	item := &entity.Goods{ProjectId: 1, Name: "First item", Description: "Original"}
+	err := s.repo.CreateItem(ctx, item)
+	require.NoError(s.T(), err)
+	item.Description = "Broken"
+	_, err = s.repo.UpdateItem(ctx, item)
+	require.NoError(s.T(), err)
+	_, err = s.repo.DeleteItem(ctx, item.Id)
+	require.NoError(s.T(), err)
+	revisions, err := s.repo.GetItemRevisions(ctx, item.Id)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), revisions, 3)
+	assert.Equal(s.T(), revisions[1].Action, entity.Update)
+	changes, err := entity.DiffGoods(&revisions[0].Goods, revisions[1].Goods)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), changes, []entity.FieldChange{{Field: "description", From: "Original", To: "Broken"}})
+	reverted := &entity.Goods{Id: item.Id}
+	prev, patch, err := s.repo.RevertItem(ctx, reverted, revisions[0].Revision)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), patch.Empty())
+	assert.Equal(s.T(), reverted.Description, "Original")
+	assert.Equal(s.T(), prev.Description, "Broken")
+	assert.True(s.T(), prev.Removed)
+	assert.False(s.T(), reverted.Removed)
+	revisions, err = s.repo.GetItemRevisions(ctx, item.Id)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), revisions, 4)
+	assert.Equal(s.T(), *revisions[3].RevertedFrom, revisions[0].Revision)
+	_, _, err = s.repo.RevertItem(ctx, &entity.Goods{Id: item.Id}, 100)
+	require.ErrorIs(s.T(), err, entity.ErrRevisionNotFound)
+	_, err = s.repo.GetItemRevisions(ctx, 100)
+	require.ErrorIs(s.T(), err, entity.ErrNotFound)
+}
+
+func (s *PostgresSuite) TestGoodsRevisionsCoverEveryWrite() {
+	ctx := context.Background()
+	created, err := s.repo.CreateItems(ctx, []entity.Goods{
+		{ProjectId: 1, Name: "First item"},
+		{ProjectId: 1, Name: "Second item"},
+	})
+	require.NoError(s.T(), err)
+	first, second := created[0].Id, created[1].Id
+	description := "Bulk"
+	_, err = s.repo.UpdateItems(ctx, entity.GoodsSelector{Ids: []int{first, second}}, entity.GoodsPatch{Description: &description})
+	require.NoError(s.T(), err)
+	_, err = s.repo.MoveItem(ctx, second, 1, 1)
+	require.NoError(s.T(), err)
+	_, err = s.repo.SetItemStatus(ctx, first, entity.GoodsActive, entity.GoodsPaused)
+	require.NoError(s.T(), err)
+	_, err = s.repo.SetItemTags(ctx, first, []string{"summer"})
+	require.NoError(s.T(), err)
+	_, err = s.repo.DeleteItems(ctx, entity.GoodsSelector{Ids: []int{first, second}})
+	require.NoError(s.T(), err)
+
+	actions := map[int][]entity.EventAction{
+		first:  {entity.Create, entity.Update, entity.Update, entity.Status, entity.Update, entity.Delete},
+		second: {entity.Create, entity.Update, entity.Move, entity.Delete},
+	}
+	for id, want := range actions {
+		item, err := s.repo.GetItem(ctx, id)
+		require.NoError(s.T(), err)
+		revisions, err := s.repo.GetItemRevisions(ctx, id)
+		require.NoError(s.T(), err)
+		got := make([]entity.EventAction, 0, len(revisions))
+		for i, rev := range revisions {
+			assert.Equal(s.T(), i+1, rev.Revision)
+			got = append(got, rev.Action)
+		}
+		assert.Equal(s.T(), want, got)
+		assert.Equal(s.T(), item.Version, revisions[len(revisions)-1].Revision)
+	}
+}
+
+func (s *PostgresSuite) TestPriorityPerProject() {
+	ctx := context.Background()
+	project := &entity.Project{Name: "Second project"}
+	err := s.repo.AddProject(ctx, project)
+	require.NoError(s.T(), err)
+	projects, err := s.repo.GetProjects(ctx, entity.ProjectFilter{}, firstPage)
+	require.NoError(s.T(), err)
+	secondId := projects.Project[len(projects.Project)-1].Id
+	goods := []*entity.Goods{
+		{ProjectId: 1, Name: "First item"},
+		{ProjectId: secondId, Name: "Second item"},
+		{ProjectId: 1, Name: "Three item"},
+	}
+	for _, val := range goods {
+		err := s.repo.CreateItem(ctx, val)
+		require.NoError(s.T(), err)
+	}
+	items, err := s.repo.GetItemsByProject(ctx, 1, false, "", firstPage)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), items.Goods, 2)
+	assert.Equal(s.T(), items.Goods[0].Priority, 1)
+	assert.Equal(s.T(), items.Goods[1].Priority, 2)
+	items, err = s.repo.GetItemsByProject(ctx, secondId, false, "", firstPage)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), items.Goods, 1)
+	assert.Equal(s.T(), items.Goods[0].Priority, 1)
+}
+
+func (s *PostgresSuite) TestPaginateGoods() {
+	ctx := context.Background()
+	for _, name := range []string{"First item", "Second item", "Three item"} {
+		err := s.repo.CreateItem(ctx, &entity.Goods{ProjectId: 1, Name: name})
+		require.NoError(s.T(), err)
+	}
+	page := entity.Page{Limit: 2}
+	first, err := s.repo.GetAllItems(ctx, activeGoods, page)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), first.Goods, 2)
+	assert.Equal(s.T(), first.Meta.Total, 3)
+	require.NotEmpty(s.T(), first.Meta.NextCursor)
+	page.After, err = entity.DecodeCursor(first.Meta.NextCursor)
+	require.NoError(s.T(), err)
+	second, err := s.repo.GetAllItems(ctx, activeGoods, page)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), second.Goods, 1)
+	assert.Equal(s.T(), second.Goods[0].Name, "Three item")
+	assert.Empty(s.T(), second.Meta.NextCursor)
+}
+
+func (s *PostgresSuite) TestFilterGoods() {
+	ctx := context.Background()
+	for _, name := range []string{"a item", "c item", "b item"} {
+		err := s.repo.CreateItem(ctx, &entity.Goods{ProjectId: 1, Name: name})
+		require.NoError(s.T(), err)
+	}
+	priorityLt := 3
+	filter := entity.GoodsFilter{
+		PriorityLt: &priorityLt,
+		Sort:       []entity.GoodsSort{{Field: entity.SortByName, Desc: true}},
+	}
+	items, err := s.repo.GetAllItems(ctx, filter, firstPage)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), items.Goods, 2)
+	assert.Equal(s.T(), items.Goods[0].Name, "c item")
+	assert.Equal(s.T(), items.Goods[1].Name, "a item")
+
+	filter = entity.GoodsFilter{Sort: []entity.GoodsSort{{Field: entity.SortByName}}}
+	page := entity.Page{Limit: 1}
+	items, err = s.repo.GetAllItems(ctx, filter, page)
+	require.NoError(s.T(), err)
+	page.After, err = entity.DecodeCursor(items.Meta.NextCursor)
+	require.NoError(s.T(), err)
+	items, err = s.repo.GetAllItems(ctx, filter, page)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), items.Goods, 1)
+	assert.Equal(s.T(), items.Goods[0].Name, "b item")
+}
+
+func (s *PostgresSuite) TestSearchGoods() {
+	ctx := context.Background()
+	goods := []*entity.Goods{
+		{ProjectId: 1, Name: "Red shoes", Description: "Comfortable running shoes"},
+		{ProjectId: 1, Name: "Blue hat"},
+	}
+	for _, val := range goods {
+		err := s.repo.CreateItem(ctx, val)
+		require.NoError(s.T(), err)
+	}
+	res, err := s.repo.SearchItems(ctx, "running", false, firstPage)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), res.Hits, 1)
+	assert.Equal(s.T(), res.Hits[0].Name, "Red shoes")
+	assert.Contains(s.T(), res.Hits[0].Snippet, "<b>")
+	assert.Equal(s.T(), res.Meta.Total, 1)
+}
+
+func (s *PostgresSuite) TestSuggestGoods() {
+	ctx := context.Background()
+	goods := []*entity.Goods{
+		{ProjectId: 1, Name: "Red shoes"},
+		{ProjectId: 1, Name: "Blue hat"},
+	}
+	for _, val := range goods {
+		err := s.repo.CreateItem(ctx, val)
+		require.NoError(s.T(), err)
+		assert.NotZero(s.T(), val.Id)
+	}
+	res, err := s.repo.SuggestItems(ctx, "shoess", 1, 10)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), res, 1)
+	assert.Equal(s.T(), entity.Suggestion{Id: goods[0].Id, Name: "Red shoes"}, res[0])
+}
+
+func (s *PostgresSuite) TestCreateItemsBulk() {
+	ctx := context.Background()
+	err := s.repo.CreateItem(ctx, &entity.Goods{ProjectId: 1, Name: "first"})
+	require.NoError(s.T(), err)
+
+	items, err := s.repo.CreateItems(ctx, []entity.Goods{
+		{ProjectId: 1, Name: "bulk 1"},
+		{ProjectId: 1, Name: "bulk 2", Description: "second"},
+		{ProjectId: 1, Name: "bulk 3"},
+	})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), items, 3)
+	for i, item := range items {
+		assert.Equal(s.T(), fmt.Sprintf("bulk %d", i+1), item.Name)
+		assert.Equal(s.T(), i+2, item.Priority)
+		assert.NotZero(s.T(), item.Id)
+	}
+	assert.Equal(s.T(), "second", items[1].Description)
+
+	_, err = s.repo.CreateItems(ctx, []entity.Goods{
+		{ProjectId: 1, Name: "ok"},
+		{ProjectId: 999, Name: "missing project"},
+	})
+	var bulkErr *entity.BulkError
+	require.ErrorAs(s.T(), err, &bulkErr)
+	require.Len(s.T(), bulkErr.Rows, 1)
+	assert.Equal(s.T(), 1, bulkErr.Rows[0].Index)
+
+	all, err := s.repo.GetItemsByProject(ctx, 1, false, "", firstPage)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), all.Goods, 4)
+}
+
+func (s *PostgresSuite) TestBulkUpdateDeleteGoods() {
+	ctx := context.Background()
+	_, err := s.repo.CreateItems(ctx, []entity.Goods{
+		{ProjectId: 1, Name: "a"},
+		{ProjectId: 1, Name: "b"},
+		{ProjectId: 1, Name: "c"},
+	})
+	require.NoError(s.T(), err)
+
+	description := "bulk"
+	patch := entity.GoodsPatch{Description: &description}
+	_, err = s.repo.UpdateItems(ctx, entity.GoodsSelector{Ids: []int{1, 42}}, patch)
+	var missing *entity.MissingIdsError
+	require.ErrorAs(s.T(), err, &missing)
+	assert.Equal(s.T(), []int{42}, missing.Ids)
+	item, err := s.repo.GetItem(ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), item.Description)
+
+	updated, err := s.repo.UpdateItems(ctx, entity.GoodsSelector{Ids: []int{1, 2}}, patch)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), updated, 2)
+	assert.Empty(s.T(), updated[0].Prev.Description)
+	assert.Equal(s.T(), description, updated[0].Item.Description)
+
+	deleted, err := s.repo.DeleteItems(ctx, entity.GoodsSelector{Filter: activeGoods})
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), deleted, 3)
+	_, err = s.repo.DeleteItems(ctx, entity.GoodsSelector{Ids: []int{1}})
+	assert.ErrorIs(s.T(), err, entity.ErrNotFound)
+}
+
+func (s *PostgresSuite) TestUpsertItemsAndImportJob() {
+	ctx := context.Background()
+	err := s.repo.CreateItem(ctx, &entity.Goods{ProjectId: 1, Name: "existing"})
 	require.NoError(s.T(), err)
-	assert.Len(s.T(), allItems, 3)
-	namedItems, err := s.repo.GetItemsByName(ctx, "item")
+
+	created, updated, err := s.repo.UpsertItems(ctx, 1, []entity.Goods{
+		{Name: "existing", Description: "imported"},
+		{Name: "new", Description: "fresh"},
+	})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), updated, 1)
+	assert.Empty(s.T(), updated[0].Prev.Description)
+	assert.Equal(s.T(), "imported", updated[0].Item.Description)
+	require.Len(s.T(), created, 1)
+	assert.Equal(s.T(), "new", created[0].Name)
+	assert.Equal(s.T(), 2, created[0].Priority)
+
+	job := &entity.ImportJob{ProjectId: 1, Status: entity.ImportPending, Total: 2}
+	require.NoError(s.T(), s.repo.CreateImportJob(ctx, job))
+	job.Status = entity.ImportDone
+	job.Processed, job.Created, job.Updated = 2, 1, 1
+	require.NoError(s.T(), s.repo.UpdateImportJob(ctx, job))
+	assert.NotNil(s.T(), job.FinishedAt)
+
+	got, err := s.repo.GetImportJob(ctx, job.Id)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), entity.ImportDone, got.Status)
+	assert.Equal(s.T(), 2, got.Processed)
+
+	stale := &entity.ImportJob{ProjectId: 1, Status: entity.ImportRunning, Total: 2}
+	require.NoError(s.T(), s.repo.CreateImportJob(ctx, stale))
+	failed, err := s.repo.FailUnfinishedImportJobs(ctx, entity.ImportInterrupted)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, failed)
+	got, err = s.repo.GetImportJob(ctx, stale.Id)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), entity.ImportFailed, got.Status)
+	assert.Equal(s.T(), entity.ImportInterrupted, got.Error)
+	assert.NotNil(s.T(), got.FinishedAt)
+}
+
+func (s *PostgresSuite) TestStreamGoods() {
+	ctx := context.Background()
+	_, err := s.repo.CreateItems(ctx, []entity.Goods{
+		{ProjectId: 1, Name: "a"},
+		{ProjectId: 1, Name: "b"},
+		{ProjectId: 1, Name: "c"},
+	})
+	require.NoError(s.T(), err)
+	_, err = s.repo.DeleteItem(ctx, 2)
+	require.NoError(s.T(), err)
+
+	var names []string
+	err = s.repo.StreamItems(ctx, activeGoods, func(item entity.Goods) error {
+		names = append(names, item.Name)
+		return nil
+	})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"a", "c"}, names)
+}
+
+func (s *PostgresSuite) TestUpdateItemVersion() {
+	ctx := context.Background()
+	item := &entity.Goods{ProjectId: 1, Name: "versioned"}
+	require.NoError(s.T(), s.repo.CreateItem(ctx, item))
+	assert.Equal(s.T(), 1, item.Version)
+
+	update := &entity.Goods{Id: item.Id, Name: "first", Priority: 1, Version: 1}
+	_, err := s.repo.UpdateItem(ctx, update)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, update.Version)
+
+	stale := &entity.Goods{Id: item.Id, Name: "second", Priority: 1, Version: 1}
+	_, err = s.repo.UpdateItem(ctx, stale)
+	require.ErrorIs(s.T(), err, entity.ErrVersionConflict)
+	assert.Equal(s.T(), "first", stale.Name)
+	assert.Equal(s.T(), 2, stale.Version)
+}
+
+func (s *PostgresSuite) TestPatchItem() {
+	ctx := context.Background()
+	item := &entity.Goods{ProjectId: 1, Name: "patched", Description: "old"}
+	require.NoError(s.T(), s.repo.CreateItem(ctx, item))
+
+	description := "new"
+	name := "patched"
+	input := &entity.Goods{Id: item.Id}
+	_, changes, err := s.repo.PatchItem(ctx, input, entity.GoodsPatch{Name: &name, Description: &description})
 	require.NoError(s.T(), err)
-	assert.Len(s.T(), namedItems, 3)
-	namedItems, err = s.repo.GetItemsByName(ctx, "three")
+	assert.Nil(s.T(), changes.Name)
+	require.NotNil(s.T(), changes.Description)
+	assert.Equal(s.T(), "new", input.Description)
+	assert.Equal(s.T(), "patched", input.Name)
+	assert.Equal(s.T(), 2, input.Version)
+
+	input = &entity.Goods{Id: item.Id}
+	_, changes, err = s.repo.PatchItem(ctx, input, entity.GoodsPatch{Description: &description})
+	require.NoError(s.T(), err)
+	assert.True(s.T(), changes.Empty())
+	assert.Equal(s.T(), 2, input.Version)
+
+	input = &entity.Goods{Id: item.Id}
+	_, changes, err = s.repo.PatchItem(ctx, input, entity.GoodsPatch{ClearDescription: true})
 	require.NoError(s.T(), err)
-	assert.Len(s.T(), namedItems, 1)
-	assert.Equal(s.T(), namedItems[0].Name, goods[2].Name)
+	assert.True(s.T(), changes.ClearDescription)
+	assert.Equal(s.T(), "", input.Description)
+	assert.Equal(s.T(), 3, input.Version)
+
+	var cleared bool
+	require.NoError(s.T(), s.PgPool.QueryRow(ctx, "SELECT description IS NULL FROM goods WHERE id = $1", item.Id).Scan(&cleared))
+	assert.True(s.T(), cleared)
 }
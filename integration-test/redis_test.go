@@ -97,3 +97,31 @@ func (s *RedisSuite) TestItemsRedis() {
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), result, goods)
 }
+
+func (s *RedisSuite) TestAutocompleteRedis() {
+	defer s.repo.AutocompleteClear(entity.SuggestGoods)
+	err := s.repo.AutocompleteAdd(entity.SuggestGoods, 1, entity.Suggestion{Id: 1, Name: "Red Shoes"})
+	require.NoError(s.T(), err)
+	err = s.repo.AutocompleteAdd(entity.SuggestGoods, 2, entity.Suggestion{Id: 2, Name: "Blue shirt"})
+	require.NoError(s.T(), err)
+
+	res, err := s.repo.AutocompleteSearch(entity.SuggestGoods, "sh", 0, 10)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), res, 2)
+
+	res, err = s.repo.AutocompleteSearch(entity.SuggestGoods, "sh", 1, 10)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []entity.Suggestion{{Id: 1, Name: "Red Shoes"}}, res)
+
+	require.NoError(s.T(), s.repo.CleanCache())
+	err = s.repo.AutocompleteAdd(entity.SuggestGoods, 1, entity.Suggestion{Id: 1, Name: "Green boots"})
+	require.NoError(s.T(), err)
+	res, err = s.repo.AutocompleteSearch(entity.SuggestGoods, "sh", 0, 10)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []entity.Suggestion{{Id: 2, Name: "Blue shirt"}}, res)
+
+	require.NoError(s.T(), s.repo.AutocompleteRemoveProject(2))
+	res, err = s.repo.AutocompleteSearch(entity.SuggestGoods, "b", 0, 10)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []entity.Suggestion{{Id: 1, Name: "Green boots"}}, res)
+}
@@ -32,6 +32,8 @@ type App struct {
 	router    *gin.Engine
 	logger    *logger.Logger
 	work      *worker.ClickHouseWorker
+	scheduler *scheduler
+	importer  *importer
 }
 
 func New(cfg *config.Config) (*App, error) {
@@ -58,22 +60,55 @@ func New(cfg *config.Config) (*App, error) {
 		logger.Fatal("failed create conn to nats", err)
 	}
 	event := events.New(ns)
-	repo := repository.New(redisClient, pgpool, event)
+	ch, err := clickHouse.NewClickHouse(app.config.Clickhouse)
+	if err != nil {
+		logger.Fatal("failed conn ch", err)
+	}
+	repo := repository.New(redisClient, pgpool, event, ch)
 	service := usecase.New(repo)
 	handler := controller.New(service)
 
 	app.router.GET("/goods", handler.GetAll)
 	app.router.GET("/goods/:id", handler.GetItem)
+	app.router.GET("/goods/search", handler.SearchItems)
+	app.router.GET("/goods/export", handler.ExportItems)
 	app.router.GET("/goods/search/:name", handler.GetItemsByName)
 	app.router.GET("/:project_id/goods", handler.GetItemsByProject)
 	app.router.PATCH("/goods", handler.UpdateItem)
+	app.router.PATCH("/goods/:id", handler.PatchItem)
 	app.router.POST("/goods", handler.CreateItem)
+	app.router.POST("/goods/bulk", handler.CreateItems)
+	app.router.PATCH("/goods/bulk", handler.UpdateItems)
+	app.router.DELETE("/goods/bulk", handler.DeleteItems)
 	app.router.DELETE("/goods/:id", handler.DeleteItem)
+	app.router.POST("/goods/:id/restore", handler.RestoreItem)
+	app.router.GET("/goods/:id/revisions", handler.GetItemRevisions)
+	app.router.GET("/goods/:id/history", handler.GetItemHistory)
+	app.router.POST("/goods/:id/revert/:revision", handler.RevertItem)
+	app.router.PATCH("/goods/:id/reprioritize", handler.ReprioritizeItem)
+	app.router.POST("/goods/:id/move", handler.MoveItem)
+	app.router.POST("/goods/:id/status", handler.SetItemStatus)
+	app.router.PUT("/goods/:id/window", handler.SetItemWindow)
+	app.router.PUT("/goods/:id/tags", handler.SetItemTags)
+	app.router.GET("/tags", handler.GetTags)
+	app.router.GET("/tags/:id", handler.GetTag)
+	app.router.POST("/tags", handler.CreateTag)
+	app.router.PATCH("/tags/:id", handler.UpdateTag)
+	app.router.DELETE("/tags/:id", handler.DeleteTag)
+	app.router.GET("/autocomplete", handler.Autocomplete)
 	app.router.GET("/projects", handler.GetProjects)
 	app.router.GET("/projects/:id", handler.GetProject)
+	app.router.GET("/projects/:id/summary", handler.GetProjectSummary)
+	app.router.GET("/projects/:id/history", handler.GetProjectHistory)
+	app.router.GET("/projects/:id/goods", handler.GetProjectGoodsAsOf)
 	app.router.POST("/projects", handler.CreateProject)
 	app.router.PATCH("/projects/:id", handler.UpdateProject)
 	app.router.DELETE("/projects/:id", handler.DeleteProject)
+	app.router.POST("/projects/:id/clone", handler.CloneProject)
+	app.router.POST("/projects/:id/status", handler.SetProjectStatus)
+	app.router.POST("/projects/:id/goods/import", handler.ImportItems)
+	app.router.GET("/imports/:id", handler.GetImportJob)
+	app.router.GET("/audit", handler.GetAudit)
 
 	host := app.config.APIServer.Host
 	port := app.config.APIServer.Port
@@ -84,14 +119,12 @@ func New(cfg *config.Config) (*App, error) {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
-	ch, err := clickHouse.NewClickHouse(app.config.Clickhouse)
-	if err != nil {
-		logger.Fatal("failed conn ch", err)
-	}
 	work, err := worker.NewClickHouseWorker(ns.Conn, ch.Conn, "db.events.>")
 	if err != nil {
 		logger.Fatal("failed init worker", err)
 	}
+	app.scheduler = newScheduler(service, cfg.AppConfig.SchedulerInterval)
+	app.importer = newImporter(service)
 	app.closer = NewCloser(pgpool, redisClient, event, work)
 	app.work = work
 	app.logger.Info("Application initialized successfully")
@@ -102,6 +135,7 @@ func (app *App) Run() error {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	app.importer.Start()
 	go func() {
 		app.logger.Info("API server started successfully", "address", app.apiServer.Addr)
 		if err := app.apiServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -109,6 +143,7 @@ func (app *App) Run() error {
 		}
 	}()
 	go app.work.Start()
+	app.scheduler.Start()
 
 	<-ctx.Done()
 	app.logger.Info("Received shutdown signal")
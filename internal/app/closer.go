@@ -38,6 +38,12 @@ func (c *closer) Close(app *App) error {
 		}
 	}
 
+	if app.scheduler != nil {
+		app.scheduler.Close()
+	}
+	if app.importer != nil {
+		app.importer.Close()
+	}
 	c.postgres.Close()
 	c.redis.Close()
 	c.nats.Close()
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"sync"
+
+	"github.com/paxaf/HezzlTest/internal/logger"
+	"github.com/paxaf/HezzlTest/internal/usecase"
+)
+
+// importer runs the queued import jobs until the app is closed. Jobs left
+// unfinished by a previous run are failed before new ones are accepted.
+type importer struct {
+	service usecase.Usecase
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func newImporter(service usecase.Usecase) *importer {
+	return &importer{service: service}
+}
+
+// Start has to run before the API server accepts imports.
+func (i *importer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	i.cancel = cancel
+
+	if err := i.service.FailUnfinishedImports(ctx); err != nil {
+		logger.Error("failed fail unfinished imports", err)
+	}
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		i.service.RunImports(ctx)
+	}()
+}
+
+func (i *importer) Close() {
+	if i.cancel == nil {
+		return
+	}
+	i.cancel()
+	i.wg.Wait()
+}
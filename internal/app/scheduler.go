@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/paxaf/HezzlTest/internal/logger"
+	"github.com/paxaf/HezzlTest/internal/usecase"
+)
+
+const defaultSchedulerInterval = time.Minute
+
+// scheduler flips goods to active or expired when their activation window
+// opens or closes. A good changes status at most one interval late.
+type scheduler struct {
+	service  usecase.Usecase
+	interval time.Duration
+	shutdown chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newScheduler(service usecase.Usecase, interval time.Duration) *scheduler {
+	if interval <= 0 {
+		interval = defaultSchedulerInterval
+	}
+	return &scheduler{
+		service:  service,
+		interval: interval,
+		shutdown: make(chan struct{}),
+	}
+}
+
+func (s *scheduler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.tick()
+		for {
+			select {
+			case <-ticker.C:
+				s.tick()
+			case <-s.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+func (s *scheduler) tick() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+	defer cancel()
+
+	changed, err := s.service.ApplyGoodsWindows(ctx, time.Now())
+	if err != nil {
+		logger.Error("failed apply goods windows", err)
+	}
+	if changed > 0 {
+		logger.Info("Goods windows applied", "changed", changed)
+	}
+}
+
+func (s *scheduler) Close() {
+	close(s.shutdown)
+	s.wg.Wait()
+}
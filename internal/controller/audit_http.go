@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/paxaf/HezzlTest/internal/logger"
+)
+
+// parseAuditFilter reads the GET /audit query, the history endpoints share
+// action, from and to.
+func parseAuditFilter(c *gin.Context) (entity.AuditFilter, error) {
+	var f entity.AuditFilter
+	var err error
+	f.Entity = c.Query("entity")
+	if f.Entity != "" && f.Entity != entity.AuditGoods && f.Entity != entity.AuditProject {
+		return f, fmt.Errorf("entity must be %s or %s", entity.AuditGoods, entity.AuditProject)
+	}
+	f.Action = entity.EventAction(c.Query("action"))
+	if f.Action != "" && !f.Action.Valid() {
+		return f, fmt.Errorf("unknown action %q", f.Action)
+	}
+	f.Field = c.Query("field")
+	if f.ProjectId, err = queryInt(c, "project_id"); err != nil {
+		return f, err
+	}
+	if f.From, err = queryTime(c, "from"); err != nil {
+		return f, err
+	}
+	if f.To, err = queryTime(c, "to"); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+func (h *handler) auditEvents(c *gin.Context, filter entity.AuditFilter) {
+	ctx := c.Request.Context()
+	page, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	output, err := h.service.GetAuditEvents(ctx, filter, page)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+			return
+		}
+		logger.Error("getauditevents error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *handler) GetAudit(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	h.auditEvents(c, filter)
+}
+
+// GetItemHistory lists the events of a good, deleted goods keep their history.
+func (h *handler) GetItemHistory(c *gin.Context) {
+	h.entityHistory(c, entity.AuditGoods)
+}
+
+// GetProjectHistory lists the events of the project itself, events of its
+// goods are found with GET /audit?project_id=.
+func (h *handler) GetProjectHistory(c *gin.Context) {
+	h.entityHistory(c, entity.AuditProject)
+}
+
+// GetProjectGoodsAsOf rebuilds the goods of a project at as_of from the
+// event log, removed goods are hidden unless include_removed is set.
+func (h *handler) GetProjectGoodsAsOf(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	asOf, err := queryTime(c, "as_of")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	if asOf == nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: as_of is required"})
+		return
+	}
+	withRemoved, err := includeRemoved(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: include_removed must be a boolean"})
+		return
+	}
+	output, err := h.service.GetProjectGoodsAsOf(ctx, id, *asOf, withRemoved)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		logger.Error("getprojectgoodsasof error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *handler) entityHistory(c *gin.Context, name string) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	filter.Entity, filter.EntityId = name, &id
+	h.auditEvents(c, filter)
+}
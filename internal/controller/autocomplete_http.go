@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/paxaf/HezzlTest/internal/logger"
+)
+
+func (h *handler) Autocomplete(c *gin.Context) {
+	ctx := c.Request.Context()
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: q is required"})
+		return
+	}
+	scope := entity.SuggestScope(c.DefaultQuery("scope", string(entity.SuggestGoods)))
+	if !scope.Valid() {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: scope must be goods or projects"})
+		return
+	}
+	var projectId int
+	if projectStr := c.Query("project_id"); projectStr != "" {
+		id, err := strconv.Atoi(projectStr)
+		if err != nil || id < 1 || scope != entity.SuggestGoods {
+			c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: invalid project_id"})
+			return
+		}
+		projectId = id
+	}
+	limit := entity.DefaultSuggestLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		val, err := strconv.Atoi(limitStr)
+		if err != nil || val < 1 || val > entity.MaxSuggestLimit {
+			c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: limit must be between 1 and " + strconv.Itoa(entity.MaxSuggestLimit)})
+			return
+		}
+		limit = val
+	}
+	output, err := h.service.Autocomplete(ctx, scope, query, projectId, limit)
+	if err != nil {
+		logger.Error("autocomplete error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
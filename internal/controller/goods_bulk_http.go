@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/paxaf/HezzlTest/internal/logger"
+)
+
+type BulkUpdateRequest struct {
+	Ids         []int   `json:"ids"`
+	Description *string `json:"description"`
+	Removed     *bool   `json:"removed"`
+}
+
+type BulkDeleteRequest struct {
+	Ids []int `json:"ids"`
+}
+
+type bulkErrorResponse struct {
+	Error string            `json:"error"`
+	Rows  []entity.RowError `json:"rows"`
+}
+
+type missingIdsResponse struct {
+	Error string `json:"error"`
+	Ids   []int  `json:"ids"`
+}
+
+var errNoSelector = errors.New("ids or a filter condition is required")
+
+// parseGoodsSelector selects goods by ids from the body or, without ids, by
+// the GET /goods filter in the query string. A filter without conditions is
+// rejected, removed, include_removed and sort alone never select goods, so a
+// bare request cannot touch every good.
+func parseGoodsSelector(c *gin.Context, ids []int) (entity.GoodsSelector, error) {
+	var sel entity.GoodsSelector
+	if len(ids) > 0 {
+		if len(ids) > entity.MaxBulkItems {
+			return sel, fmt.Errorf("at most %d ids allowed", entity.MaxBulkItems)
+		}
+		for _, id := range ids {
+			if id < 1 {
+				return sel, fmt.Errorf("ids must be positive")
+			}
+		}
+		sel.Ids = ids
+		return sel, nil
+	}
+	filter, err := parseGoodsFilter(c)
+	if err != nil {
+		return sel, err
+	}
+	if !filter.Narrowed() {
+		return sel, errNoSelector
+	}
+	sel.Filter = filter
+	return sel, nil
+}
+
+func bulkFailed(c *gin.Context, msg string, err error) {
+	var missing *entity.MissingIdsError
+	if errors.As(err, &missing) {
+		c.JSON(http.StatusNotFound, missingIdsResponse{Error: "Not found", Ids: missing.Ids})
+		return
+	}
+	if errors.Is(err, entity.ErrProjectArchived) {
+		c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+		return
+	}
+	logger.Error(msg, err)
+	c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+}
+
+func (h *handler) CreateItems(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req []CreateRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Invalid request format: " + err.Error()})
+		return
+	}
+	if len(req) == 0 || len(req) > entity.MaxBulkItems {
+		c.JSON(http.StatusBadRequest, errorResponse{
+			Error: "Bad request: expected from 1 to " + strconv.Itoa(entity.MaxBulkItems) + " goods",
+		})
+		return
+	}
+	var rowErrs []entity.RowError
+	input := make([]entity.Goods, 0, len(req))
+	for i := range req {
+		if err := binding.Validator.ValidateStruct(&req[i]); err != nil {
+			rowErrs = append(rowErrs, entity.RowError{Index: i, Error: err.Error()})
+			continue
+		}
+		if !entity.ValidWindow(req[i].ActiveFrom, req[i].ActiveTo) {
+			rowErrs = append(rowErrs, entity.RowError{Index: i, Error: errWindow})
+			continue
+		}
+		if err := entity.ValidatePrice(req[i].Price, req[i].Currency); err != nil {
+			rowErrs = append(rowErrs, entity.RowError{Index: i, Error: err.Error()})
+			continue
+		}
+		if err := entity.ValidateAttributes(req[i].Attributes); err != nil {
+			rowErrs = append(rowErrs, entity.RowError{Index: i, Error: err.Error()})
+			continue
+		}
+		input = append(input, entity.Goods{
+			ProjectId:   req[i].ProjectID,
+			Description: req[i].Description,
+			Name:        req[i].Name,
+			ActiveFrom:  req[i].ActiveFrom,
+			ActiveTo:    req[i].ActiveTo,
+			Price:       req[i].Price,
+			Currency:    req[i].Currency,
+			Attributes:  req[i].Attributes,
+		})
+	}
+	if len(rowErrs) > 0 {
+		c.JSON(http.StatusBadRequest, bulkErrorResponse{Error: "Bad request", Rows: rowErrs})
+		return
+	}
+	output, err := h.service.CreateItems(ctx, input)
+	if err != nil {
+		var bulkErr *entity.BulkError
+		if errors.As(err, &bulkErr) {
+			c.JSON(http.StatusBadRequest, bulkErrorResponse{Error: "Bad request", Rows: bulkErr.Rows})
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
+		logger.Error("createitems error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusCreated, output)
+}
+
+func (h *handler) UpdateItems(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req BulkUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Invalid request format: " + err.Error()})
+		return
+	}
+	if req.Description == nil && req.Removed == nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: nothing to update"})
+		return
+	}
+	sel, err := parseGoodsSelector(c, req.Ids)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	patch := entity.GoodsPatch{
+		Description: req.Description,
+		Removed:     req.Removed,
+	}
+	output, err := h.service.UpdateItems(ctx, sel, patch)
+	if err != nil {
+		bulkFailed(c, "updateitems error", err)
+		return
+	}
+	c.JSON(http.StatusOK, append([]entity.Goods{}, output...))
+}
+
+func (h *handler) DeleteItems(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req BulkDeleteRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Invalid request format: " + err.Error()})
+		return
+	}
+	sel, err := parseGoodsSelector(c, req.Ids)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	output, err := h.service.DeleteItems(ctx, sel)
+	if err != nil {
+		bulkFailed(c, "deleteitems error", err)
+		return
+	}
+	c.JSON(http.StatusOK, append([]entity.Goods{}, output...))
+}
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/paxaf/HezzlTest/internal/logger"
+	"github.com/shopspring/decimal"
+)
+
+const exportFlushRows = 500
+
+var exportColumns = []string{"id", "project_id", "name", "description", "priority", "removed", "removed_at",
+	"status", "active_from", "active_to", "price", "currency", "attributes", "created_at"}
+
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"ndjson": "application/x-ndjson",
+	"xlsx":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+type goodsEncoder interface {
+	Encode(item entity.Goods) error
+	Flush() error
+	Close() error
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatPrice(price *decimal.Decimal, currency *string) (string, string) {
+	if price == nil || currency == nil {
+		return "", ""
+	}
+	return price.String(), *currency
+}
+
+// formatAttributes writes the attributes as a JSON object.
+func formatAttributes(attrs map[string]interface{}) string {
+	if len(attrs) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+type csvGoodsEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvGoodsEncoder) Encode(item entity.Goods) error {
+	price, currency := formatPrice(item.Price, item.Currency)
+	return e.w.Write([]string{
+		strconv.Itoa(item.Id),
+		strconv.Itoa(item.ProjectId),
+		item.Name,
+		item.Description,
+		strconv.Itoa(item.Priority),
+		strconv.FormatBool(item.Removed),
+		formatOptionalTime(item.RemovedAt),
+		string(item.Status),
+		formatOptionalTime(item.ActiveFrom),
+		formatOptionalTime(item.ActiveTo),
+		price,
+		currency,
+		formatAttributes(item.Attributes),
+		item.CreatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (e *csvGoodsEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvGoodsEncoder) Close() error {
+	return e.Flush()
+}
+
+type ndjsonGoodsEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonGoodsEncoder) Encode(item entity.Goods) error {
+	return e.enc.Encode(item)
+}
+
+func (e *ndjsonGoodsEncoder) Flush() error { return nil }
+func (e *ndjsonGoodsEncoder) Close() error { return nil }
+
+type xlsxGoodsEncoder struct {
+	w *xlsxWriter
+}
+
+func (e *xlsxGoodsEncoder) Encode(item entity.Goods) error {
+	price, currency := formatPrice(item.Price, item.Currency)
+	return e.w.WriteRow(
+		xlsxNumber(item.Id),
+		xlsxNumber(item.ProjectId),
+		xlsxString(item.Name),
+		xlsxString(item.Description),
+		xlsxNumber(item.Priority),
+		xlsxBool(item.Removed),
+		xlsxString(formatOptionalTime(item.RemovedAt)),
+		xlsxString(string(item.Status)),
+		xlsxString(formatOptionalTime(item.ActiveFrom)),
+		xlsxString(formatOptionalTime(item.ActiveTo)),
+		xlsxString(price),
+		xlsxString(currency),
+		xlsxString(formatAttributes(item.Attributes)),
+		xlsxString(item.CreatedAt.UTC().Format(time.RFC3339)),
+	)
+}
+
+func (e *xlsxGoodsEncoder) Flush() error { return e.w.Flush() }
+func (e *xlsxGoodsEncoder) Close() error { return e.w.Close() }
+
+// newGoodsEncoder writes the header row where the format has one.
+func newGoodsEncoder(format string, w io.Writer) (goodsEncoder, error) {
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportColumns); err != nil {
+			return nil, err
+		}
+		return &csvGoodsEncoder{w: cw}, nil
+	case "ndjson":
+		return &ndjsonGoodsEncoder{enc: json.NewEncoder(w)}, nil
+	case "xlsx":
+		xw, err := newXLSXWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		header := make([]xlsxCell, 0, len(exportColumns))
+		for _, col := range exportColumns {
+			header = append(header, xlsxString(col))
+		}
+		if err = xw.WriteRow(header...); err != nil {
+			return nil, err
+		}
+		return &xlsxGoodsEncoder{w: xw}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// ExportItems streams goods matching the GET /goods filters. The response
+// starts with the first row, so a failing query still gets a JSON error,
+// while a failure mid-stream can only cut the body short.
+func (h *handler) ExportItems(c *gin.Context) {
+	ctx := c.Request.Context()
+	format := c.DefaultQuery("format", "csv")
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: format must be csv, ndjson or xlsx"})
+		return
+	}
+	filter, err := parseGoodsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+
+	var enc goodsEncoder
+	start := func() error {
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", `attachment; filename="goods.`+format+`"`)
+		c.Status(http.StatusOK)
+		var err error
+		enc, err = newGoodsEncoder(format, c.Writer)
+		return err
+	}
+	rows := 0
+	err = h.service.ExportItems(ctx, filter, func(item entity.Goods) error {
+		if enc == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := enc.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("exportitems error", err)
+		if !c.Writer.Written() {
+			c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		}
+		return
+	}
+	if enc == nil {
+		if err = start(); err != nil {
+			logger.Error("exportitems error", err)
+			return
+		}
+	}
+	if err = enc.Close(); err != nil {
+		logger.Error("exportitems error", err)
+		return
+	}
+	c.Writer.Flush()
+}
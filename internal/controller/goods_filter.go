@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+func queryInt(c *gin.Context, name string) (*int, error) {
+	str, ok := c.GetQuery(name)
+	if !ok {
+		return nil, nil
+	}
+	val, err := strconv.Atoi(str)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", name)
+	}
+	return &val, nil
+}
+
+func queryBool(c *gin.Context, name string) (*bool, error) {
+	str, ok := c.GetQuery(name)
+	if !ok {
+		return nil, nil
+	}
+	val, err := strconv.ParseBool(str)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a boolean", name)
+	}
+	return &val, nil
+}
+
+func queryTime(c *gin.Context, name string) (*time.Time, error) {
+	str, ok := c.GetQuery(name)
+	if !ok {
+		return nil, nil
+	}
+	val, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+	val = val.UTC()
+	return &val, nil
+}
+
+func queryDecimal(c *gin.Context, name string) (*decimal.Decimal, error) {
+	str, ok := c.GetQuery(name)
+	if !ok {
+		return nil, nil
+	}
+	val, err := decimal.NewFromString(str)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a decimal number", name)
+	}
+	return &val, nil
+}
+
+func parseGoodsSort(str string) ([]entity.GoodsSort, error) {
+	if str == "" {
+		return nil, nil
+	}
+	seen := make(map[entity.GoodsSortField]bool)
+	var res []entity.GoodsSort
+	for _, part := range strings.Split(str, ",") {
+		var s entity.GoodsSort
+		if strings.HasPrefix(part, "-") {
+			s.Desc = true
+			part = part[1:]
+		}
+		s.Field = entity.GoodsSortField(part)
+		if !s.Field.Valid() {
+			return nil, fmt.Errorf("unknown sort field %q", part)
+		}
+		if seen[s.Field] {
+			return nil, fmt.Errorf("duplicate sort field %q", part)
+		}
+		seen[s.Field] = true
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// parseGoodsFilter reads the GET /goods query language. Removed goods are
+// hidden unless removed or include_removed is given explicitly.
+func parseGoodsFilter(c *gin.Context) (entity.GoodsFilter, error) {
+	var f entity.GoodsFilter
+	var err error
+	if f.ProjectId, err = queryInt(c, "project_id"); err != nil {
+		return f, err
+	}
+	if f.ProjectId != nil && *f.ProjectId < 1 {
+		return f, fmt.Errorf("project_id must be positive")
+	}
+	if f.Removed, err = queryBool(c, "removed"); err != nil {
+		return f, err
+	}
+	if f.Removed == nil {
+		withRemoved, err := includeRemoved(c)
+		if err != nil {
+			return f, fmt.Errorf("include_removed must be a boolean")
+		}
+		if !withRemoved {
+			f.Removed = new(bool)
+		}
+	}
+	if f.CreatedAfter, err = queryTime(c, "created_after"); err != nil {
+		return f, err
+	}
+	if f.CreatedBefore, err = queryTime(c, "created_before"); err != nil {
+		return f, err
+	}
+	if f.PriorityGt, err = queryInt(c, "priority_gt"); err != nil {
+		return f, err
+	}
+	if f.PriorityLt, err = queryInt(c, "priority_lt"); err != nil {
+		return f, err
+	}
+	if f.PriceMin, err = queryDecimal(c, "price_min"); err != nil {
+		return f, err
+	}
+	if f.PriceMax, err = queryDecimal(c, "price_max"); err != nil {
+		return f, err
+	}
+	f.Currency = c.Query("currency")
+	f.Tag = c.Query("tag")
+	for key, vals := range c.Request.URL.Query() {
+		name, ok := strings.CutPrefix(key, "attr.")
+		if !ok {
+			continue
+		}
+		if name == "" {
+			return f, fmt.Errorf("attribute name is required in %q", key)
+		}
+		if f.Attributes == nil {
+			f.Attributes = make(map[string]string)
+		}
+		f.Attributes[name] = vals[0]
+	}
+	if f.Sort, err = parseGoodsSort(c.Query("sort")); err != nil {
+		return f, err
+	}
+	return f, nil
+}
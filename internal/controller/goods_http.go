@@ -4,37 +4,79 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/paxaf/HezzlTest/internal/entity"
 	"github.com/paxaf/HezzlTest/internal/logger"
+	"github.com/shopspring/decimal"
 )
 
 type CreateRequest struct {
-	ProjectID   int    `json:"project_id" binding:"required,gt=0"`
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
+	ProjectID   int                    `json:"project_id" binding:"required,gt=0"`
+	Name        string                 `json:"name" binding:"required"`
+	Description string                 `json:"description"`
+	ActiveFrom  *time.Time             `json:"active_from"`
+	ActiveTo    *time.Time             `json:"active_to"`
+	Price       *decimal.Decimal       `json:"price"`
+	Currency    *string                `json:"currency"`
+	Attributes  map[string]interface{} `json:"attributes"`
 }
 
+// UpdateRequset replaces the price and the attributes only when they are
+// given.
 type UpdateRequset struct {
-	Id          int    `json:"id" binding:"required,gt=0"`
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	Priority    int    `json:"priority" binding:"required,gt=0"`
-	Removed     bool   `json:"removed" binding:"required"`
+	Id          int                    `json:"id" binding:"required,gt=0"`
+	Name        string                 `json:"name" binding:"required"`
+	Description string                 `json:"description"`
+	Priority    int                    `json:"priority" binding:"required,gt=0"`
+	Removed     bool                   `json:"removed"`
+	Price       *decimal.Decimal       `json:"price"`
+	Currency    *string                `json:"currency"`
+	Attributes  map[string]interface{} `json:"attributes"`
+}
+
+type ReprioritizeRequest struct {
+	Priority int `json:"priority" binding:"required,gt=0"`
+}
+
+type MoveRequest struct {
+	ProjectID int `json:"project_id" binding:"required,gt=0"`
+	Priority  int `json:"priority" binding:"omitempty,gt=0"`
+}
+
+type priorityResponse struct {
+	Id       int `json:"id"`
+	Priority int `json:"priority"`
+}
+
+func includeRemoved(c *gin.Context) (bool, error) {
+	return strconv.ParseBool(c.DefaultQuery("include_removed", "false"))
 }
 
 func (h *handler) GetAll(c *gin.Context) {
 	ctx := c.Request.Context()
-	key := c.Request.URL.String()
-	output, err := h.service.GetAllItems(ctx, key)
+	filter, err := parseGoodsFilter(c)
 	if err != nil {
-		logger.Error("getall rrror", err)
-		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
 		return
 	}
-	if output == nil {
-		c.JSON(http.StatusOK, map[string]interface{}{})
+	page, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	key := "goods?" + filter.Key() + "&" + page.Key()
+	output, err := h.service.GetAllItems(ctx, key, filter, page)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+			return
+		}
+		logger.Error("getall rrror", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
 		return
 	}
 	c.JSON(http.StatusOK, output)
@@ -59,6 +101,7 @@ func (h *handler) GetItem(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
 		return
 	}
+	c.Header("ETag", etag(output.Version))
 	c.JSON(http.StatusOK, output)
 }
 
@@ -66,14 +109,51 @@ func (h *handler) GetItemsByName(c *gin.Context) {
 	ctx := c.Request.Context()
 	key := c.Request.URL.String()
 	name := c.Param("name")
-	output, err := h.service.GetItemsByName(ctx, key, name)
+	withRemoved, err := includeRemoved(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	page, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	output, err := h.service.GetItemsByName(ctx, key, name, withRemoved, c.Query("tag"), page)
 	if err != nil {
+		if errors.Is(err, entity.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+			return
+		}
 		logger.Error("getitemsbyname error", err)
 		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
 		return
 	}
-	if output == nil {
-		c.JSON(http.StatusOK, map[string]interface{}{})
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *handler) SearchItems(c *gin.Context) {
+	ctx := c.Request.Context()
+	key := c.Request.URL.String()
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: q is required"})
+		return
+	}
+	withRemoved, err := includeRemoved(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	page, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	output, err := h.service.SearchItems(ctx, key, query, withRemoved, page)
+	if err != nil {
+		logger.Error("searchitems error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
 		return
 	}
 	c.JSON(http.StatusOK, output)
@@ -88,14 +168,24 @@ func (h *handler) GetItemsByProject(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
 		return
 	}
-	output, err := h.service.GetItemsByProject(ctx, key, projectId)
+	withRemoved, err := includeRemoved(c)
 	if err != nil {
-		logger.Error("getitemsbyproject error", err)
-		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	page, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
 		return
 	}
-	if output == nil {
-		c.JSON(http.StatusOK, map[string]interface{}{})
+	output, err := h.service.GetItemsByProject(ctx, key, projectId, withRemoved, c.Query("tag"), page)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+			return
+		}
+		logger.Error("getitemsbyproject error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
 		return
 	}
 	c.JSON(http.StatusOK, output)
@@ -111,13 +201,34 @@ func (h *handler) CreateItem(c *gin.Context) {
 		})
 		return
 	}
+	if !entity.ValidWindow(req.ActiveFrom, req.ActiveTo) {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: errWindow})
+		return
+	}
+	if err := entity.ValidatePrice(req.Price, req.Currency); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	if err := entity.ValidateAttributes(req.Attributes); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
 	input := entity.Goods{
 		ProjectId:   req.ProjectID,
 		Description: req.Description,
 		Name:        req.Name,
+		ActiveFrom:  req.ActiveFrom,
+		ActiveTo:    req.ActiveTo,
+		Price:       req.Price,
+		Currency:    req.Currency,
+		Attributes:  req.Attributes,
 	}
 	err := h.service.CreateItem(ctx, &input)
 	if err != nil {
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
 		logger.Error("createitem error", err)
 		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
 		return
@@ -132,27 +243,101 @@ func (h *handler) UpdateItem(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
 		return
 	}
+	if err := entity.ValidatePrice(req.Price, req.Currency); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	if err := entity.ValidateAttributes(req.Attributes); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	version, err := ifMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
 	input := entity.Goods{
 		Id:          req.Id,
 		Name:        req.Name,
 		Description: req.Description,
 		Removed:     req.Removed,
 		Priority:    req.Priority,
+		Version:     version,
+		Price:       req.Price,
+		Currency:    req.Currency,
+		Attributes:  req.Attributes,
 	}
 
-	err := h.service.UpdateItem(ctx, &input)
+	err = h.service.UpdateItem(ctx, &input)
 	if err != nil {
 		if errors.Is(err, entity.ErrNotFound) {
 			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
 			return
 		}
+		if errors.Is(err, entity.ErrVersionConflict) {
+			c.Header("ETag", etag(input.Version))
+			c.JSON(http.StatusPreconditionFailed, input)
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
 		logger.Error("update error", err)
 		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
 		return
 	}
+	c.Header("ETag", etag(input.Version))
 	c.Status(http.StatusNoContent)
 }
 
+// PatchItem applies an RFC 7396 merge patch, only the fields present in the
+// body are written.
+func (h *handler) PatchItem(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	if ct := c.ContentType(); ct != mimeMergePatch && ct != binding.MIMEJSON {
+		c.JSON(http.StatusUnsupportedMediaType, errorResponse{Error: "Content-Type must be " + mimeMergePatch})
+		return
+	}
+	patch, err := parseGoodsPatch(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	version, err := ifMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	input := entity.Goods{Id: id, Version: version}
+	err = h.service.PatchItem(ctx, &input, patch)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		if errors.Is(err, entity.ErrVersionConflict) {
+			c.Header("ETag", etag(input.Version))
+			c.JSON(http.StatusPreconditionFailed, input)
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
+		logger.Error("patchitem error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.Header("ETag", etag(input.Version))
+	c.JSON(http.StatusOK, input)
+}
+
 func (h *handler) DeleteItem(c *gin.Context) {
 	ctx := c.Request.Context()
 	idStr := c.Param("id")
@@ -167,9 +352,106 @@ func (h *handler) DeleteItem(c *gin.Context) {
 			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
 			return
 		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
 		logger.Error("deleteitem error", err)
 		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
+
+func (h *handler) RestoreItem(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	output, err := h.service.RestoreItem(ctx, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
+		logger.Error("restoreitem error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *handler) ReprioritizeItem(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	var req ReprioritizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	output, err := h.service.ReprioritizeItem(ctx, id, req.Priority)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
+		logger.Error("reprioritize error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	res := make([]priorityResponse, 0, len(output))
+	for _, item := range output {
+		res = append(res, priorityResponse{Id: item.Id, Priority: item.Priority})
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *handler) MoveItem(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	var req MoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	output, err := h.service.MoveItem(ctx, id, req.ProjectID, req.Priority)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		if errors.Is(err, entity.ErrProjectNotFound) {
+			c.JSON(http.StatusUnprocessableEntity, errorResponse{Error: "Target project not found"})
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
+		logger.Error("moveitem error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
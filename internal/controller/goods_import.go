@@ -0,0 +1,235 @@
+package controller
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/paxaf/HezzlTest/internal/logger"
+)
+
+const (
+	maxImportBytes   = 32 << 20
+	maxNDJSONLine    = 1 << 20
+	mimeCSV          = "text/csv"
+	mimeNDJSON       = "application/x-ndjson"
+	mimeNDJSONLegacy = "application/ndjson"
+)
+
+// importMapping names the CSV columns or NDJSON keys holding goods fields.
+type importMapping struct {
+	name        string
+	description string
+}
+
+func parseImportMapping(c *gin.Context) importMapping {
+	return importMapping{
+		name:        c.DefaultQuery("name_column", "name"),
+		description: c.DefaultQuery("description_column", "description"),
+	}
+}
+
+type importParser struct {
+	projectId int
+	items     []entity.Goods
+	report    entity.ImportReport
+}
+
+func (p *importParser) fail(index int, msg string) {
+	p.report.Total++
+	p.report.Errors = append(p.report.Errors, entity.RowError{Index: index, Error: msg})
+}
+
+func (p *importParser) add(index int, name, description string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		p.fail(index, "name is required")
+		return
+	}
+	p.report.Total++
+	p.report.Valid++
+	p.items = append(p.items, entity.Goods{
+		ProjectId:   p.projectId,
+		Name:        name,
+		Description: description,
+	})
+}
+
+func (p *importParser) parseCSV(r io.Reader, m importMapping) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed read csv header: %w", err)
+	}
+	nameCol, descCol := -1, -1
+	for i, col := range header {
+		col = strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))
+		switch {
+		case strings.EqualFold(col, m.name):
+			nameCol = i
+		case strings.EqualFold(col, m.description):
+			descCol = i
+		}
+	}
+	if nameCol < 0 {
+		return fmt.Errorf("column %q not found", m.name)
+	}
+	for index := 0; ; index++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			p.fail(index, parseErr.Err.Error())
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if nameCol >= len(record) {
+			p.fail(index, "name is required")
+			continue
+		}
+		var description string
+		if descCol >= 0 && descCol < len(record) {
+			description = record[descCol]
+		}
+		p.add(index, record[nameCol], description)
+	}
+}
+
+func (p *importParser) parseNDJSON(r io.Reader, m importMapping) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+	index := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var row map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			p.fail(index, "invalid json object")
+			index++
+			continue
+		}
+		name, ok := row[m.name].(string)
+		if !ok && row[m.name] != nil {
+			p.fail(index, m.name+" must be a string")
+			index++
+			continue
+		}
+		description, ok := row[m.description].(string)
+		if !ok && row[m.description] != nil {
+			p.fail(index, m.description+" must be a string")
+			index++
+			continue
+		}
+		p.add(index, name, description)
+		index++
+	}
+	return scanner.Err()
+}
+
+// ImportItems validates the uploaded file synchronously and hands valid rows
+// to a background job. With dry_run only the validation report is returned.
+func (h *handler) ImportItems(c *gin.Context) {
+	ctx := c.Request.Context()
+	projectId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || projectId < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	dryRun, err := queryBool(c, "dry_run")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	upsert, err := queryBool(c, "upsert")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+
+	parser := &importParser{
+		projectId: projectId,
+		report:    entity.ImportReport{Errors: []entity.RowError{}},
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	switch c.ContentType() {
+	case mimeCSV:
+		err = parser.parseCSV(body, parseImportMapping(c))
+	case mimeNDJSON, mimeNDJSONLegacy:
+		err = parser.parseNDJSON(body, parseImportMapping(c))
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, errorResponse{Error: "Content-Type must be " + mimeCSV + " or " + mimeNDJSON})
+		return
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, errorResponse{Error: "File too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	if parser.report.Total == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: file has no rows"})
+		return
+	}
+	if dryRun != nil && *dryRun {
+		c.JSON(http.StatusOK, parser.report)
+		return
+	}
+	if len(parser.report.Errors) > 0 {
+		c.JSON(http.StatusBadRequest, bulkErrorResponse{Error: "Bad request", Rows: parser.report.Errors})
+		return
+	}
+
+	output, err := h.service.ImportItems(ctx, projectId, parser.items, upsert != nil && *upsert)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
+		logger.Error("importitems error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusAccepted, output)
+}
+
+func (h *handler) GetImportJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	output, err := h.service.GetImportJob(ctx, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		logger.Error("getimportjob error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
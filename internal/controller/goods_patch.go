@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+const mimeMergePatch = "application/merge-patch+json"
+
+// parseGoodsPatch reads a merge patch document. A null description clears it,
+// the other fields are not nullable and unknown members are rejected. Priority
+// is refused, moving a good shifts its neighbours and has its own endpoint. An empty
+// document is a valid no-op. Attributes are merged one level deep, a null
+// attribute removes it. Price and currency go together, a null price removes
+// both.
+func parseGoodsPatch(r io.Reader) (entity.GoodsPatch, error) {
+	var patch entity.GoodsPatch
+	var doc map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return patch, fmt.Errorf("body must be a json object")
+	}
+	if err := parsePricePatch(doc, &patch); err != nil {
+		return patch, err
+	}
+	for key, raw := range doc {
+		null := string(raw) == "null"
+		switch key {
+		case "name":
+			var name string
+			if null || json.Unmarshal(raw, &name) != nil || name == "" {
+				return patch, fmt.Errorf("name must be a non-empty string")
+			}
+			patch.Name = &name
+		case "description":
+			if null {
+				patch.ClearDescription = true
+				continue
+			}
+			var description string
+			if json.Unmarshal(raw, &description) != nil {
+				return patch, fmt.Errorf("description must be a string or null")
+			}
+			patch.Description = &description
+		case "priority":
+			return patch, fmt.Errorf("priority can not be patched, use PATCH /goods/:id/reprioritize")
+		case "removed":
+			var removed bool
+			if null || json.Unmarshal(raw, &removed) != nil {
+				return patch, fmt.Errorf("removed must be a boolean")
+			}
+			patch.Removed = &removed
+		case "attributes":
+			var attrs map[string]interface{}
+			if null || json.Unmarshal(raw, &attrs) != nil {
+				return patch, fmt.Errorf("attributes must be an object")
+			}
+			if err := entity.ValidateAttributes(attrs); err != nil {
+				return patch, err
+			}
+			patch.Attributes = attrs
+		case "price", "currency":
+		default:
+			return patch, fmt.Errorf("unknown field %q", key)
+		}
+	}
+	return patch, nil
+}
+
+func parsePricePatch(doc map[string]json.RawMessage, patch *entity.GoodsPatch) error {
+	rawPrice, hasPrice := doc["price"]
+	rawCurrency, hasCurrency := doc["currency"]
+	if !hasPrice && !hasCurrency {
+		return nil
+	}
+	if string(rawPrice) == "null" {
+		if hasCurrency && string(rawCurrency) != "null" {
+			return fmt.Errorf("currency must be null when price is null")
+		}
+		patch.ClearPrice = true
+		return nil
+	}
+	var price decimal.Decimal
+	var currency string
+	if !hasPrice || json.Unmarshal(rawPrice, &price) != nil {
+		return fmt.Errorf("price must be a decimal number or null")
+	}
+	if !hasCurrency || json.Unmarshal(rawCurrency, &currency) != nil {
+		return fmt.Errorf("currency must be a string")
+	}
+	if err := entity.ValidatePrice(&price, &currency); err != nil {
+		return err
+	}
+	patch.Price, patch.Currency = &price, &currency
+	return nil
+}
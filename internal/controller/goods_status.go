@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/paxaf/HezzlTest/internal/logger"
+)
+
+const errWindow = "Bad request: active_from must be before active_to"
+
+type GoodsStatusRequest struct {
+	Status entity.GoodsStatus `json:"status" binding:"required"`
+}
+
+// WindowRequest replaces both bounds, a missing or null bound leaves the
+// window open on that side.
+type WindowRequest struct {
+	ActiveFrom *time.Time `json:"active_from"`
+	ActiveTo   *time.Time `json:"active_to"`
+}
+
+func (h *handler) SetItemStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	var req GoodsStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !req.Status.Valid() {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	output, err := h.service.SetItemStatus(ctx, id, req.Status)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		var transitionErr *entity.TransitionError
+		if errors.As(err, &transitionErr) {
+			c.JSON(http.StatusConflict, errorResponse{Error: transitionErr.Error()})
+			return
+		}
+		if errors.Is(err, entity.ErrVersionConflict) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Status changed concurrently"})
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
+		logger.Error("setitemstatus error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *handler) SetItemWindow(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	var req WindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	if !entity.ValidWindow(req.ActiveFrom, req.ActiveTo) {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: errWindow})
+		return
+	}
+	output, err := h.service.SetItemWindow(ctx, id, req.ActiveFrom, req.ActiveTo)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
+		logger.Error("setitemwindow error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
@@ -1,6 +1,12 @@
 package controller
 
 import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/paxaf/HezzlTest/internal/entity"
 	"github.com/paxaf/HezzlTest/internal/usecase"
 )
 
@@ -17,3 +23,47 @@ func New(service usecase.Usecase) *handler {
 type errorResponse struct {
 	Error string `json:"error"`
 }
+
+var errInvalidLimit = errors.New("limit must be between 1 and " + strconv.Itoa(entity.MaxPageLimit))
+
+func parsePage(c *gin.Context) (entity.Page, error) {
+	page := entity.Page{Limit: entity.DefaultPageLimit}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > entity.MaxPageLimit {
+			return page, errInvalidLimit
+		}
+		page.Limit = limit
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		after, err := entity.DecodeCursor(cursor)
+		if err != nil {
+			return page, err
+		}
+		page.After = after
+	}
+	return page, nil
+}
+
+var errInvalidIfMatch = errors.New("If-Match must be a quoted version or *")
+
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatch returns the version required by the If-Match header, zero when the
+// header is absent or "*" so the update is applied unconditionally.
+func ifMatch(c *gin.Context) (int, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	if len(header) < 3 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.Atoi(header[1 : len(header)-1])
+	if err != nil || version < 1 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
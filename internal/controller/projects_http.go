@@ -14,6 +14,10 @@ type CreateProject struct {
 	Name string `json:"name" binding:"required"`
 }
 
+type StatusRequest struct {
+	Status entity.ProjectStatus `json:"status" binding:"required"`
+}
+
 type UpdateProject struct {
 	Id   int    `json:"id" binding:"required,gt=0"`
 	Name string `json:"name" binding:"required"`
@@ -39,20 +43,48 @@ func (h *handler) GetProject(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
 		return
 	}
+	c.Header("ETag", etag(output.Version))
+	c.JSON(http.StatusOK, output)
+}
+
+// GetProjectSummary reports price statistics of the live goods per currency.
+func (h *handler) GetProjectSummary(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	output, err := h.service.GetProjectSummary(ctx, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		logger.Error("getprojectsummary error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
 	c.JSON(http.StatusOK, output)
 }
 
 func (h *handler) GetProjects(c *gin.Context) {
 	ctx := c.Request.Context()
 	key := c.Request.URL.String()
-	output, err := h.service.GetProjects(ctx, key)
+	page, err := parsePage(c)
 	if err != nil {
-		logger.Error("getprojects error", err)
-		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	filter := entity.ProjectFilter{Status: entity.ProjectStatus(c.Query("status"))}
+	if filter.Status != "" && !filter.Status.Valid() {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: unknown status " + string(filter.Status)})
 		return
 	}
-	if output == nil {
-		c.JSON(http.StatusOK, map[string]interface{}{})
+	output, err := h.service.GetProjects(ctx, key, filter, page)
+	if err != nil {
+		logger.Error("getprojects error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
 		return
 	}
 	c.JSON(http.StatusOK, output)
@@ -87,21 +119,33 @@ func (h *handler) UpdateProject(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
 		return
 	}
+	version, err := ifMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
 	input := entity.Project{
-		Id:   req.Id,
-		Name: req.Name,
+		Id:      req.Id,
+		Name:    req.Name,
+		Version: version,
 	}
 
-	err := h.service.UpdateProject(ctx, &input)
+	err = h.service.UpdateProject(ctx, &input)
 	if err != nil {
 		if errors.Is(err, entity.ErrNotFound) {
 			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
 			return
 		}
+		if errors.Is(err, entity.ErrVersionConflict) {
+			c.Header("ETag", etag(input.Version))
+			c.JSON(http.StatusPreconditionFailed, input)
+			return
+		}
 		logger.Error("update proj error", err)
 		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
 		return
 	}
+	c.Header("ETag", etag(input.Version))
 	c.Status(http.StatusNoContent)
 }
 
@@ -113,15 +157,85 @@ func (h *handler) DeleteProject(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
 		return
 	}
-	err = h.service.DeleteProject(ctx, id)
+	force, err := queryBool(c, "force")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	err = h.service.DeleteProject(ctx, id, force != nil && *force)
 	if err != nil {
 		if errors.Is(err, entity.ErrNotFound) {
 			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
 			return
 		}
+		if errors.Is(err, entity.ErrProjectNotEmpty) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project has goods, use ?force=true to delete them too"})
+			return
+		}
 		logger.Error("deleteproject error", err)
 		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
+
+func (h *handler) CloneProject(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	var req CreateProject
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	output, err := h.service.CloneProject(ctx, id, req.Name)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		logger.Error("cloneproject error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusCreated, output)
+}
+
+func (h *handler) SetProjectStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	var req StatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !req.Status.Valid() {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	output, err := h.service.SetProjectStatus(ctx, id, req.Status)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		var transitionErr *entity.TransitionError
+		if errors.As(err, &transitionErr) {
+			c.JSON(http.StatusConflict, errorResponse{Error: transitionErr.Error()})
+			return
+		}
+		if errors.Is(err, entity.ErrVersionConflict) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project status changed concurrently"})
+			return
+		}
+		logger.Error("setprojectstatus error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
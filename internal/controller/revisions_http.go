@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/paxaf/HezzlTest/internal/logger"
+)
+
+func (h *handler) GetItemRevisions(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	output, err := h.service.GetItemRevisions(ctx, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		logger.Error("getitemrevisions error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
+
+// RevertItem restores the content of a revision, If-Match is honoured as in
+// PatchItem.
+func (h *handler) RevertItem(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	version, err := ifMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: " + err.Error()})
+		return
+	}
+	input := entity.Goods{Id: id, Version: version}
+	err = h.service.RevertItem(ctx, &input, revision)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		if errors.Is(err, entity.ErrRevisionNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Revision not found"})
+			return
+		}
+		if errors.Is(err, entity.ErrVersionConflict) {
+			c.Header("ETag", etag(input.Version))
+			c.JSON(http.StatusPreconditionFailed, input)
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
+		logger.Error("revertitem error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.Header("ETag", etag(input.Version))
+	c.JSON(http.StatusOK, input)
+}
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/paxaf/HezzlTest/internal/logger"
+)
+
+type TagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type ItemTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+func (h *handler) GetTags(c *gin.Context) {
+	ctx := c.Request.Context()
+	output, err := h.service.GetTags(ctx)
+	if err != nil {
+		logger.Error("gettags error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *handler) GetTag(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	output, err := h.service.GetTag(ctx, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		logger.Error("gettag error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *handler) CreateTag(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	input := entity.Tag{Name: strings.TrimSpace(req.Name)}
+	err := h.service.CreateTag(ctx, &input)
+	if err != nil {
+		if errors.Is(err, entity.ErrTagExists) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Tag already exists"})
+			return
+		}
+		logger.Error("createtag error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusCreated, input)
+}
+
+func (h *handler) UpdateTag(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	input := entity.Tag{Id: id, Name: strings.TrimSpace(req.Name)}
+	err = h.service.RenameTag(ctx, &input)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		if errors.Is(err, entity.ErrTagExists) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Tag already exists"})
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Tag is used in an archived project"})
+			return
+		}
+		logger.Error("updatetag error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, input)
+}
+
+func (h *handler) DeleteTag(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	err = h.service.DeleteTag(ctx, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Tag is used in an archived project"})
+			return
+		}
+		logger.Error("deletetag error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SetItemTags replaces the tags of a good, names not known yet become new
+// tags and an empty list removes all of them.
+func (h *handler) SetItemTags(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	var req ItemTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request"})
+		return
+	}
+	if len(req.Tags) > entity.MaxGoodsTags {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Bad request: at most " + strconv.Itoa(entity.MaxGoodsTags) + " tags"})
+		return
+	}
+	output, err := h.service.SetItemTags(ctx, id, req.Tags)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse{Error: "Not found"})
+			return
+		}
+		if errors.Is(err, entity.ErrProjectArchived) {
+			c.JSON(http.StatusConflict, errorResponse{Error: "Project is archived"})
+			return
+		}
+		logger.Error("setitemtags error", err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Error: "Internal error"})
+		return
+	}
+	c.Header("ETag", etag(output.Version))
+	c.JSON(http.StatusOK, output)
+}
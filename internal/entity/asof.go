@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// GoodsAsOf is a good rebuilt from the event log, every field holds the
+// latest value an event carried before the moment. Known is false when the
+// log has no create event for the good, its history predates the log and
+// fields no event carried are null. Tags and attributes are not rebuilt,
+// partial updates log only the changed attributes and an empty tag list is
+// not logged at all.
+type GoodsAsOf struct {
+	Id          int              `json:"id"`
+	ProjectId   int              `json:"project_id"`
+	Known       bool             `json:"known"`
+	Name        *string          `json:"name"`
+	Description *string          `json:"description"`
+	Priority    *int             `json:"priority"`
+	Removed     *bool            `json:"removed"`
+	Status      *string          `json:"status"`
+	ActiveFrom  *time.Time       `json:"active_from,omitempty"`
+	ActiveTo    *time.Time       `json:"active_to,omitempty"`
+	Price       *decimal.Decimal `json:"price"`
+	Currency    *string          `json:"currency"`
+	CreatedAt   *time.Time       `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// ProjectGoodsAsOf is the project rebuilt from the event log. Known is false
+// when the log has no event of the project itself before the moment, the
+// project predates the log.
+type ProjectGoodsAsOf struct {
+	ProjectId int         `json:"project_id"`
+	AsOf      time.Time   `json:"as_of"`
+	Known     bool        `json:"known"`
+	Goods     []GoodsAsOf `json:"goods"`
+}
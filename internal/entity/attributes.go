@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const (
+	// MaxGoodsAttributes limits the number of top level attribute keys.
+	MaxGoodsAttributes  = 50
+	maxAttributeKeySize = 100
+)
+
+// ValidateAttributes checks the keys of custom goods attributes, the values
+// may be any JSON.
+func ValidateAttributes(attrs map[string]interface{}) error {
+	if len(attrs) > MaxGoodsAttributes {
+		return fmt.Errorf("at most %d attributes", MaxGoodsAttributes)
+	}
+	for key := range attrs {
+		if key == "" || len(key) > maxAttributeKeySize {
+			return fmt.Errorf("attribute keys must be from 1 to %d bytes", maxAttributeKeySize)
+		}
+	}
+	return nil
+}
+
+// attributeChanges drops the patch keys that would not change current, a nil
+// value removes the key.
+func attributeChanges(patch, current map[string]interface{}) map[string]interface{} {
+	var res map[string]interface{}
+	for key, val := range patch {
+		old, ok := current[key]
+		if ok && reflect.DeepEqual(old, val) || !ok && val == nil {
+			continue
+		}
+		if res == nil {
+			res = make(map[string]interface{})
+		}
+		res[key] = val
+	}
+	return res
+}
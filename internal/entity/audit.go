@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Entity names the events are written with.
+const (
+	AuditGoods   = "good"
+	AuditProject = "project"
+)
+
+func (a EventAction) Valid() bool {
+	switch a {
+	case Create, Update, Delete, Restore, Move, Status:
+		return true
+	}
+	return false
+}
+
+// AuditFilter selects stored events, zero fields match everything. From is
+// inclusive, To is exclusive. Field matches updates that changed the field.
+type AuditFilter struct {
+	Entity    string
+	EntityId  *int
+	Action    EventAction
+	Field     string
+	ProjectId *int
+	From      *time.Time
+	To        *time.Time
+}
+
+// AuditEvent is an event read back from the log. Fields the event did not
+// carry are null, as in the payload it was written from.
+type AuditEvent struct {
+	Timestamp     time.Time        `json:"timestamp"`
+	Action        EventAction      `json:"action"`
+	Entity        string           `json:"entity"`
+	EntityId      int              `json:"entity_id"`
+	ProjectId     int              `json:"project_id"`
+	FromProjectId *int             `json:"from_project_id,omitempty"`
+	Name          string           `json:"name,omitempty"`
+	Description   *string          `json:"description,omitempty"`
+	Priority      *int             `json:"priority,omitempty"`
+	Removed       *bool            `json:"removed,omitempty"`
+	Status        *string          `json:"status,omitempty"`
+	FromStatus    *string          `json:"from_status,omitempty"`
+	ActiveFrom    *time.Time       `json:"active_from,omitempty"`
+	ActiveTo      *time.Time       `json:"active_to,omitempty"`
+	Price         *decimal.Decimal `json:"price,omitempty"`
+	Currency      *string          `json:"currency,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	Attributes    json.RawMessage  `json:"attributes,omitempty"`
+	CreatedAt     *time.Time       `json:"created_at,omitempty"`
+	ChangedFields []string         `json:"changed_fields,omitempty"`
+}
+
+type AuditResponse struct {
+	Events []AuditEvent `json:"events"`
+	Meta   PageMeta     `json:"meta"`
+}
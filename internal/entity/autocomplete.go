@@ -0,0 +1,22 @@
+package entity
+
+const (
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 50
+)
+
+type SuggestScope string
+
+const (
+	SuggestGoods    SuggestScope = "goods"
+	SuggestProjects SuggestScope = "projects"
+)
+
+func (s SuggestScope) Valid() bool {
+	return s == SuggestGoods || s == SuggestProjects
+}
+
+type Suggestion struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "fmt"
+
+const MaxBulkItems = 1000
+
+type RowError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// BulkError lists every rejected row of a bulk request by its index in the
+// request body, so the client can fix them all in one go.
+type BulkError struct {
+	Rows []RowError
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("%d invalid rows", len(e.Rows))
+}
+
+// GoodsSelector picks goods for a bulk operation either by ids or by filter,
+// ids take precedence.
+type GoodsSelector struct {
+	Ids    []int
+	Filter GoodsFilter
+}
+
+// MissingIdsError reports requested ids the bulk operation could not apply to,
+// it unwraps to ErrNotFound.
+type MissingIdsError struct {
+	Ids []int
+}
+
+func (e *MissingIdsError) Error() string {
+	return fmt.Sprintf("goods not found: %v", e.Ids)
+}
+
+func (e *MissingIdsError) Unwrap() error {
+	return ErrNotFound
+}
@@ -3,14 +3,19 @@ package entity
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type EventAction string
 
 const (
-	Create EventAction = "create"
-	Update EventAction = "update"
-	Delete EventAction = "delete"
+	Create  EventAction = "create"
+	Update  EventAction = "update"
+	Delete  EventAction = "delete"
+	Restore EventAction = "restore"
+	Move    EventAction = "move"
+	Status  EventAction = "status"
 )
 
 type BaseEvent struct {
@@ -18,12 +23,20 @@ type BaseEvent struct {
 	Entity    string      `json:"entity"`
 	EntityID  int         `json:"entity_id"`
 	ProjectID int         `json:"project_id,omitempty"`
-	Timestamp time.Time   `json:"timestamp"`
+	// FromProjectID is the previous project of a moved good.
+	FromProjectID int `json:"from_project_id,omitempty"`
+	// ChangedFields names the fields that differ between Before and After
+	ChangedFields []string  `json:"changed_fields,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
 }
 
+// Event carries Before and After only for updates of goods, with every field
+// of the row on both sides.
 type Event struct {
 	BaseEvent
 	Payload interface{} `json:"payload,omitempty"`
+	Before  interface{} `json:"before,omitempty"`
+	After   interface{} `json:"after,omitempty"`
 }
 
 func (e Event) Marshal() ([]byte, error) {
@@ -34,18 +47,30 @@ type EventPayload interface {
 	ToPayload() interface{}
 }
 
+// ProjectEventPayload carries FromStatus only for status events.
 type ProjectEventPayload struct {
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
+	Name       string        `json:"name"`
+	Status     ProjectStatus `json:"status,omitempty"`
+	FromStatus ProjectStatus `json:"from_status,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
 }
 
 func (p Project) ToPayload() interface{} {
 	return ProjectEventPayload{
 		Name:      p.Name,
+		Status:    p.Status,
 		CreatedAt: p.CreatedAt,
 	}
 }
 
+func NewProjectStatusEvent(project Project, from ProjectStatus) Event {
+	payload := project.ToPayload().(ProjectEventPayload)
+	payload.FromStatus = from
+	event := NewProjectEvent(Status, project)
+	event.Payload = payload
+	return event
+}
+
 func NewProjectEvent(action EventAction, project Project) Event {
 	return Event{
 		BaseEvent: BaseEvent{
@@ -58,22 +83,97 @@ func NewProjectEvent(action EventAction, project Project) Event {
 	}
 }
 
+// GoodEventPayload carries every field for create and full update events,
+// partial update events leave unchanged fields nil.
 type GoodEventPayload struct {
-	Name        string    `json:"name"`
-	Description *string   `json:"description,omitempty"`
-	Priority    int       `json:"priority"`
-	Removed     bool      `json:"removed"`
-	CreatedAt   time.Time `json:"created_at"`
+	Name        *string          `json:"name,omitempty"`
+	Description *string          `json:"description,omitempty"`
+	Priority    *int             `json:"priority,omitempty"`
+	Removed     *bool            `json:"removed,omitempty"`
+	RemovedAt   *time.Time       `json:"removed_at,omitempty"`
+	Status      *GoodsStatus     `json:"status,omitempty"`
+	FromStatus  *GoodsStatus     `json:"from_status,omitempty"`
+	ActiveFrom  *time.Time       `json:"active_from,omitempty"`
+	ActiveTo    *time.Time       `json:"active_to,omitempty"`
+	Price       *decimal.Decimal `json:"price,omitempty"`
+	Currency    *string          `json:"currency,omitempty"`
+	Tags        []string         `json:"tags,omitempty"`
+	// Attributes of a partial update carry only the changed keys, null for
+	// removed ones
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	CreatedAt  *time.Time             `json:"created_at,omitempty"`
 }
 
 func (g Goods) ToPayload() interface{} {
 	return GoodEventPayload{
-		Name:        g.Name,
+		Name:        &g.Name,
 		Description: &g.Description,
-		Priority:    g.Priority,
-		Removed:     g.Removed,
-		CreatedAt:   g.CreatedAt,
+		Priority:    &g.Priority,
+		Removed:     &g.Removed,
+		RemovedAt:   g.RemovedAt,
+		Status:      &g.Status,
+		ActiveFrom:  g.ActiveFrom,
+		ActiveTo:    g.ActiveTo,
+		Price:       g.Price,
+		Currency:    g.Currency,
+		Tags:        g.Tags,
+		Attributes:  g.Attributes,
+		CreatedAt:   &g.CreatedAt,
+	}
+}
+
+// NewGoodUpdateEvent logs a full update of a good together with the row
+// before it.
+func NewGoodUpdateEvent(before, after Goods) Event {
+	return withDiff(NewGoodEvent(Update, after), before, after)
+}
+
+func NewGoodPatchEvent(before, goods Goods, changes GoodsPatch) Event {
+	payload := GoodEventPayload{
+		Name:        changes.Name,
+		Description: changes.Description,
+		Removed:     changes.Removed,
+		Price:       changes.Price,
+		Currency:    changes.Currency,
+		Attributes:  changes.Attributes,
+	}
+	if changes.ClearDescription {
+		payload.Description = &goods.Description
 	}
+	if changes.Removed != nil {
+		payload.RemovedAt = goods.RemovedAt
+	}
+	event := NewGoodEvent(Update, goods)
+	event.Payload = payload
+	return withDiff(event, before, goods)
+}
+
+// withDiff fills the before and after snapshots and the changed fields the
+// same way as the revision history does.
+func withDiff(event Event, before, after Goods) Event {
+	event.Before = before.ToPayload()
+	event.After = after.ToPayload()
+	// both sides are plain Goods, marshalling them cannot fail
+	changes, _ := DiffGoods(&before, after)
+	event.ChangedFields = make([]string, 0, len(changes))
+	for _, change := range changes {
+		event.ChangedFields = append(event.ChangedFields, change.Field)
+	}
+	return event
+}
+
+func NewGoodStatusEvent(goods Goods, from GoodsStatus) Event {
+	event := NewGoodEvent(Status, goods)
+	payload := event.Payload.(GoodEventPayload)
+	payload.FromStatus = &from
+	event.Payload = payload
+	return event
+}
+
+func NewGoodMoveEvent(goods Goods, fromProjectId int) Event {
+	event := NewGoodEvent(Move, goods)
+	event.FromProjectID = fromProjectId
+	return event
 }
 
 func NewGoodEvent(action EventAction, goods Goods) Event {
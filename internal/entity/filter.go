@@ -0,0 +1,161 @@
+package entity
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type GoodsSortField string
+
+const (
+	SortById        GoodsSortField = "id"
+	SortByProjectId GoodsSortField = "project_id"
+	SortByName      GoodsSortField = "name"
+	SortByPriority  GoodsSortField = "priority"
+	SortByCreatedAt GoodsSortField = "created_at"
+)
+
+func (f GoodsSortField) Valid() bool {
+	switch f {
+	case SortById, SortByProjectId, SortByName, SortByPriority, SortByCreatedAt:
+		return true
+	}
+	return false
+}
+
+type GoodsSort struct {
+	Field GoodsSortField
+	Desc  bool
+}
+
+func (s GoodsSort) String() string {
+	if s.Desc {
+		return "-" + string(s.Field)
+	}
+	return string(s.Field)
+}
+
+var DefaultGoodsSort = []GoodsSort{{Field: SortByPriority}, {Field: SortById}}
+
+type GoodsFilter struct {
+	ProjectId     *int
+	Removed       *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	PriorityGt    *int
+	PriorityLt    *int
+	// PriceMin and PriceMax are inclusive, goods without a price never match
+	PriceMin *decimal.Decimal
+	PriceMax *decimal.Decimal
+	Currency string
+	Tag      string
+	// Attributes matches goods whose attribute equals the value, compared as
+	// a string or as the JSON number or boolean it parses to
+	Attributes map[string]string
+	Sort       []GoodsSort
+}
+
+// Narrowed reports whether the filter has a condition besides removed and
+// sort, which alone still match every good.
+func (f GoodsFilter) Narrowed() bool {
+	return f.ProjectId != nil || f.CreatedAfter != nil || f.CreatedBefore != nil ||
+		f.PriorityGt != nil || f.PriorityLt != nil || f.PriceMin != nil || f.PriceMax != nil ||
+		f.Currency != "" || f.Tag != "" || len(f.Attributes) > 0
+}
+
+// OrderBy returns the requested sort with id appended as a tiebreaker, so
+// every ordering is total and can be used for keyset pagination.
+func (f GoodsFilter) OrderBy() []GoodsSort {
+	if len(f.Sort) == 0 {
+		return DefaultGoodsSort
+	}
+	res := make([]GoodsSort, 0, len(f.Sort)+1)
+	for _, s := range f.Sort {
+		res = append(res, s)
+		if s.Field == SortById {
+			return res
+		}
+	}
+	return append(res, GoodsSort{Field: SortById})
+}
+
+// Key returns a canonical representation of the filter, equal filters
+// produce equal keys regardless of query parameter order.
+func (f GoodsFilter) Key() string {
+	var parts []string
+	if f.ProjectId != nil {
+		parts = append(parts, "project_id="+strconv.Itoa(*f.ProjectId))
+	}
+	if f.Removed != nil {
+		parts = append(parts, "removed="+strconv.FormatBool(*f.Removed))
+	}
+	if f.CreatedAfter != nil {
+		parts = append(parts, "created_after="+f.CreatedAfter.UTC().Format(time.RFC3339Nano))
+	}
+	if f.CreatedBefore != nil {
+		parts = append(parts, "created_before="+f.CreatedBefore.UTC().Format(time.RFC3339Nano))
+	}
+	if f.PriorityGt != nil {
+		parts = append(parts, "priority_gt="+strconv.Itoa(*f.PriorityGt))
+	}
+	if f.PriorityLt != nil {
+		parts = append(parts, "priority_lt="+strconv.Itoa(*f.PriorityLt))
+	}
+	if f.PriceMin != nil {
+		parts = append(parts, "price_min="+f.PriceMin.String())
+	}
+	if f.PriceMax != nil {
+		parts = append(parts, "price_max="+f.PriceMax.String())
+	}
+	if f.Currency != "" {
+		parts = append(parts, "currency="+url.QueryEscape(f.Currency))
+	}
+	if f.Tag != "" {
+		parts = append(parts, "tag="+url.QueryEscape(f.Tag))
+	}
+	attrs := make([]string, 0, len(f.Attributes))
+	for key, val := range f.Attributes {
+		attrs = append(attrs, "attr."+url.QueryEscape(key)+"="+url.QueryEscape(val))
+	}
+	sort.Strings(attrs)
+	parts = append(parts, attrs...)
+	order := make([]string, 0, len(f.Sort)+1)
+	for _, s := range f.OrderBy() {
+		order = append(order, s.String())
+	}
+	parts = append(parts, "sort="+strings.Join(order, ","))
+	return strings.Join(parts, "&")
+}
+
+// SortKey identifies an ordering, a cursor is only valid for the ordering it
+// was issued for.
+func SortKey(sort []GoodsSort) string {
+	parts := make([]string, 0, len(sort))
+	for _, s := range sort {
+		parts = append(parts, s.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+func NewGoodsCursor(item Goods, sort []GoodsSort) Cursor {
+	c := Cursor{Id: item.Id, Sort: SortKey(sort)}
+	for _, s := range sort {
+		switch s.Field {
+		case SortByProjectId:
+			c.ProjectId = item.ProjectId
+		case SortByName:
+			c.Name = item.Name
+		case SortByPriority:
+			c.Priority = item.Priority
+		case SortByCreatedAt:
+			createdAt := item.CreatedAt
+			c.CreatedAt = &createdAt
+		}
+	}
+	return c
+}
@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoodsFilterNarrowed(t *testing.T) {
+	projectId := 1
+	removed := true
+	tests := []struct {
+		name   string
+		filter GoodsFilter
+		want   bool
+	}{
+		{"empty", GoodsFilter{}, false},
+		{"removed only", GoodsFilter{Removed: &removed}, false},
+		{"sort only", GoodsFilter{Sort: []GoodsSort{{Field: SortByName}}}, false},
+		{"project", GoodsFilter{ProjectId: &projectId}, true},
+		{"tag", GoodsFilter{Tag: "summer"}, true},
+		{"attribute", GoodsFilter{Attributes: map[string]string{"brand": "acme"}}, true},
+		{"currency", GoodsFilter{Currency: "EUR"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Narrowed())
+		})
+	}
+}
+
+func TestGoodsFilterKey(t *testing.T) {
+	one, two := 1, 2
+	removed := true
+	price := decimal.RequireFromString("9.90")
+	after := time.Date(2026, 1, 1, 3, 0, 0, 0, time.FixedZone("MSK", 3*3600))
+	tests := []struct {
+		name   string
+		filter GoodsFilter
+		want   string
+	}{
+		{"empty", GoodsFilter{}, "sort=priority,id"},
+		{"project", GoodsFilter{ProjectId: &one}, "project_id=1&sort=priority,id"},
+		{"removed", GoodsFilter{Removed: &removed}, "removed=true&sort=priority,id"},
+		{"created after in utc", GoodsFilter{CreatedAfter: &after}, "created_after=2026-01-01T00:00:00Z&sort=priority,id"},
+		{"priority range", GoodsFilter{PriorityGt: &one, PriorityLt: &two}, "priority_gt=1&priority_lt=2&sort=priority,id"},
+		{"price", GoodsFilter{PriceMin: &price, Currency: "EUR"}, "price_min=9.9&currency=EUR&sort=priority,id"},
+		{"tag", GoodsFilter{Tag: "a&b"}, "tag=a%26b&sort=priority,id"},
+		{"attributes sorted", GoodsFilter{Attributes: map[string]string{"size": "42", "brand": "acme"}},
+			"attr.brand=acme&attr.size=42&sort=priority,id"},
+		{"attribute escaped", GoodsFilter{Attributes: map[string]string{"a=b": "c&d"}},
+			"attr.a%3Db=c%26d&sort=priority,id"},
+		{"sort with id tiebreaker", GoodsFilter{Sort: []GoodsSort{{Field: SortByName, Desc: true}}}, "sort=-name,id"},
+		{"sort ends at id", GoodsFilter{Sort: []GoodsSort{{Field: SortById}, {Field: SortByName}}}, "sort=id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Key())
+		})
+	}
+}
+
+// TestGoodsFilterKeyDistinct guards the cache: filters matching different
+// goods must never share a key.
+func TestGoodsFilterKeyDistinct(t *testing.T) {
+	filters := []GoodsFilter{
+		{},
+		{Tag: "summer"},
+		{Tag: "winter"},
+		{Tag: "summer&attr.brand=acme"},
+		{Attributes: map[string]string{"brand": "acme"}},
+		{Attributes: map[string]string{"brand": "acme&tag=summer"}},
+		{Tag: "summer", Attributes: map[string]string{"brand": "acme"}},
+		{Attributes: map[string]string{"brand": "acme", "size": "42"}},
+		{Attributes: map[string]string{"brand=acme": ""}},
+		{Currency: "EUR"},
+		{Sort: []GoodsSort{{Field: SortByName}}},
+	}
+	seen := make(map[string]int, len(filters))
+	for i, filter := range filters {
+		key := filter.Key()
+		if j, ok := seen[key]; ok {
+			t.Errorf("filters %d and %d share the key %q", j, i, key)
+		}
+		seen[key] = i
+	}
+}
+
+func TestNewGoodsCursor(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	item := Goods{Id: 7, ProjectId: 2, Name: "apple", Priority: 3, CreatedAt: createdAt}
+	tests := []struct {
+		name string
+		sort []GoodsSort
+		want Cursor
+	}{
+		{"default", DefaultGoodsSort, Cursor{Id: 7, Priority: 3, Sort: "priority,id"}},
+		{"name desc", []GoodsSort{{Field: SortByName, Desc: true}, {Field: SortById}},
+			Cursor{Id: 7, Name: "apple", Sort: "-name,id"}},
+		{"project and created at", []GoodsSort{{Field: SortByProjectId}, {Field: SortByCreatedAt}, {Field: SortById}},
+			Cursor{Id: 7, ProjectId: 2, CreatedAt: &createdAt, Sort: "project_id,created_at,id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewGoodsCursor(item, tt.sort))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package entity
+
+import "time"
+
+// GoodsStatus is the lifecycle of a good inside its activation window, it is
+// independent of the removed flag.
+type GoodsStatus string
+
+const (
+	GoodsDraft   GoodsStatus = "draft"
+	GoodsActive  GoodsStatus = "active"
+	GoodsPaused  GoodsStatus = "paused"
+	GoodsExpired GoodsStatus = "expired"
+)
+
+func (s GoodsStatus) Valid() bool {
+	switch s {
+	case GoodsDraft, GoodsActive, GoodsPaused, GoodsExpired:
+		return true
+	}
+	return false
+}
+
+// ValidWindow reports whether the activation window is not empty, open ends
+// are always valid.
+func ValidWindow(from, to *time.Time) bool {
+	return from == nil || to == nil || from.Before(*to)
+}
+
+// WindowOpen reports whether now is inside the activation window of the good.
+func (g Goods) WindowOpen(now time.Time) bool {
+	if g.ActiveFrom != nil && now.Before(*g.ActiveFrom) {
+		return false
+	}
+	return g.ActiveTo == nil || now.Before(*g.ActiveTo)
+}
+
+// InitialStatus is the status a new good starts with: draft until its window
+// opens, expired if it has already closed.
+func (g Goods) InitialStatus(now time.Time) GoodsStatus {
+	switch {
+	case g.ActiveTo != nil && !now.Before(*g.ActiveTo):
+		return GoodsExpired
+	case g.ActiveFrom != nil && now.Before(*g.ActiveFrom):
+		return GoodsDraft
+	}
+	return GoodsActive
+}
+
+// WindowStatus is the status the good moves to once its window has been
+// changed: expired after the window closed, back to draft while an active good
+// waits for the window to open, since only the scheduler activates drafts.
+func (g Goods) WindowStatus(now time.Time) GoodsStatus {
+	switch {
+	case g.ActiveTo != nil && !now.Before(*g.ActiveTo):
+		return GoodsExpired
+	case g.Status == GoodsActive && !g.WindowOpen(now):
+		return GoodsDraft
+	}
+	return g.Status
+}
+
+// WindowChange is one status change the scheduler made once the activation
+// window opened or closed.
+type WindowChange struct {
+	Item Goods
+	From GoodsStatus
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoodsInitialStatus(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	tests := []struct {
+		name     string
+		from, to *time.Time
+		want     GoodsStatus
+	}{
+		{"no window", nil, nil, GoodsActive},
+		{"open", &past, &future, GoodsActive},
+		{"not opened", &future, nil, GoodsDraft},
+		{"closed", nil, &past, GoodsExpired},
+		{"closes now", nil, &now, GoodsExpired},
+		{"opens now", &now, nil, GoodsActive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := Goods{ActiveFrom: tt.from, ActiveTo: tt.to}
+			assert.Equal(t, tt.want, item.InitialStatus(now))
+		})
+	}
+}
+
+func TestGoodsWindowStatus(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	tests := []struct {
+		name     string
+		status   GoodsStatus
+		from, to *time.Time
+		want     GoodsStatus
+	}{
+		{"active in window", GoodsActive, &past, &future, GoodsActive},
+		{"active before window", GoodsActive, &future, nil, GoodsDraft},
+		{"active after window", GoodsActive, nil, &past, GoodsExpired},
+		{"draft before window", GoodsDraft, &future, nil, GoodsDraft},
+		{"draft in window", GoodsDraft, &past, nil, GoodsDraft},
+		{"paused before window", GoodsPaused, &future, nil, GoodsPaused},
+		{"paused after window", GoodsPaused, nil, &past, GoodsExpired},
+		{"expired reopened", GoodsExpired, nil, &future, GoodsExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := Goods{Status: tt.status, ActiveFrom: tt.from, ActiveTo: tt.to}
+			assert.Equal(t, tt.want, item.WindowStatus(now))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+const (
+	ImportChunkSize = 500
+	// ImportInterrupted is the error of jobs cut off by a shutdown or left
+	// unfinished by a previous run, their rows only lived in memory
+	ImportInterrupted = "interrupted by shutdown"
+)
+
+type ImportStatus string
+
+const (
+	ImportPending ImportStatus = "pending"
+	ImportRunning ImportStatus = "running"
+	ImportDone    ImportStatus = "done"
+	ImportFailed  ImportStatus = "failed"
+)
+
+type ImportJob struct {
+	Id         int          `json:"id"`
+	ProjectId  int          `json:"project_id"`
+	Status     ImportStatus `json:"status"`
+	Upsert     bool         `json:"upsert"`
+	Total      int          `json:"total"`
+	Processed  int          `json:"processed"`
+	Created    int          `json:"created"`
+	Updated    int          `json:"updated"`
+	Error      string       `json:"error,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+}
+
+// ImportReport is the validation result of an uploaded file, row indexes
+// count data rows from zero and skip the CSV header.
+type ImportReport struct {
+	Total  int        `json:"total"`
+	Valid  int        `json:"valid"`
+	Errors []RowError `json:"errors"`
+}
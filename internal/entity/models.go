@@ -3,30 +3,103 @@ package entity
 import (
 	"errors"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type Project struct {
-	Id        int       `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
+	Id        int           `json:"id"`
+	Name      string        `json:"name"`
+	Status    ProjectStatus `json:"status"`
+	Version   int           `json:"version"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// ProjectClone is a freshly cloned project with the number of goods copied
+// into it.
+type ProjectClone struct {
+	Project
+	GoodsCount int `json:"goods_count"`
 }
 
 type Goods struct {
-	Id          int       `json:"id"`
-	ProjectId   int       `json:"project_id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Priority    int       `json:"priority"`
-	Removed     bool      `json:"removed"`
-	CreatedAt   time.Time `json:"created_at"`
+	Id          int         `json:"id"`
+	ProjectId   int         `json:"project_id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Priority    int         `json:"priority"`
+	Removed     bool        `json:"removed"`
+	RemovedAt   *time.Time  `json:"removed_at,omitempty"`
+	Status      GoodsStatus `json:"status"`
+	ActiveFrom  *time.Time  `json:"active_from,omitempty"`
+	ActiveTo    *time.Time  `json:"active_to,omitempty"`
+	// Price is kept exact and marshalled as a JSON string
+	Price    *decimal.Decimal `json:"price"`
+	Currency *string          `json:"currency"`
+	Tags     []string         `json:"tags"`
+	// Attributes holds client defined metadata such as SKU or brand
+	Attributes map[string]interface{} `json:"attributes"`
+	Version    int                    `json:"version"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
 }
 
 type GoodsResponse struct {
-	Goods []Goods `json:"goods"`
+	Goods []Goods  `json:"goods"`
+	Meta  PageMeta `json:"meta"`
+}
+
+type GoodsSearchHit struct {
+	Goods
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+type GoodsSearchResponse struct {
+	Hits []GoodsSearchHit `json:"hits"`
+	Meta PageMeta         `json:"meta"`
+}
+
+// MoveResult is a good moved to another project together with the goods
+// whose priority was shifted in both projects. ShiftedPrev holds the shifted
+// goods as they were before the move, in the order of Shifted.
+type MoveResult struct {
+	Item          Goods   `json:"item"`
+	FromProjectId int     `json:"from_project_id"`
+	Shifted       []Goods `json:"shifted"`
+	ShiftedPrev   []Goods `json:"-"`
+}
+
+// GoodsChange is a good as a write left it together with the row as it was
+// before the write.
+type GoodsChange struct {
+	Prev Goods
+	Item Goods
+}
+
+// ChangedGoods returns the goods as the changes left them.
+func ChangedGoods(changes []GoodsChange) []Goods {
+	res := make([]Goods, 0, len(changes))
+	for _, change := range changes {
+		res = append(res, change.Item)
+	}
+	return res
 }
 
 type ProjectResponse struct {
 	Project []Project `json:"project"`
+	Meta    PageMeta  `json:"meta"`
 }
 
 var ErrNotFound = errors.New("not found")
+
+var ErrProjectNotFound = errors.New("project not found")
+
+// ErrProjectNotEmpty refuses to delete a project that still has live goods
+// unless the deletion is forced.
+var ErrProjectNotEmpty = errors.New("project not empty")
+
+// ErrVersionConflict means the row changed since the client read it, the
+// repository fills the passed entity with the current state.
+var ErrVersionConflict = errors.New("version conflict")
@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 1000
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type Cursor struct {
+	Priority  int        `json:"p,omitempty"`
+	Id        int        `json:"i"`
+	ProjectId int        `json:"pr,omitempty"`
+	Name      string     `json:"n,omitempty"`
+	CreatedAt *time.Time `json:"c,omitempty"`
+	Offset    int        `json:"o,omitempty"`
+	// EventTime and EventId address the last event of an audit page
+	EventTime *time.Time `json:"t,omitempty"`
+	EventId   string     `json:"e,omitempty"`
+	// Sort is the SortKey of the goods ordering the cursor was issued for
+	Sort string `json:"s,omitempty"`
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err = json.Unmarshal(data, &c); err != nil || c.Id < 1 && c.EventId == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+type Page struct {
+	Limit int
+	After *Cursor
+}
+
+type PageMeta struct {
+	Limit      int    `json:"limit"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func (p Page) Key() string {
+	key := "limit=" + strconv.Itoa(p.Limit)
+	if p.After != nil {
+		key += "&cursor=" + p.After.Encode()
+	}
+	return key
+}
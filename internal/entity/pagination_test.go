@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 12, 30, 0, 123456000, time.UTC)
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{"id only", Cursor{Id: 1}},
+		{"priority", Cursor{Id: 7, Priority: 3, Sort: "priority,id"}},
+		{"name", Cursor{Id: 2, Name: "яблоко & co", Sort: "name,id"}},
+		{"created at", Cursor{Id: 5, CreatedAt: &createdAt, Sort: "-created_at,id"}},
+		{"offset", Cursor{Id: 9, Offset: 100}},
+		{"audit event", Cursor{EventTime: &createdAt, EventId: "0b5d1a4e-3f0c-4c39-9a3e-6f1f0d5c2a11"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.cursor.Encode())
+			require.NoError(t, err)
+			assert.Equal(t, tt.cursor, *got)
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"not base64", "!!!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"i":1}`))},
+		{"not json", encode("cursor")},
+		{"no id", encode(`{"p":5}`)},
+		{"negative id", encode(`{"i":-1}`)},
+		{"wrong type", encode(`{"i":"1"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeCursor(tt.value)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+}
+
+func TestPageKey(t *testing.T) {
+	after := Cursor{Id: 50, Priority: 50}
+	assert.Equal(t, "limit=10", Page{Limit: 10}.Key())
+	assert.Equal(t, "limit=10&cursor="+after.Encode(), Page{Limit: 10, After: &after}.Key())
+}
@@ -0,0 +1,50 @@
+package entity
+
+import "github.com/shopspring/decimal"
+
+// GoodsPatch holds the fields of a partial update, nil fields stay untouched.
+// ClearDescription removes the description, which reads back as empty.
+// Attributes are merged key by key, a nil value removes the key. Price and
+// Currency are set together, ClearPrice removes both.
+type GoodsPatch struct {
+	Name             *string
+	Description      *string
+	ClearDescription bool
+	Removed          *bool
+	Price            *decimal.Decimal
+	Currency         *string
+	ClearPrice       bool
+	Attributes       map[string]interface{}
+}
+
+func (p GoodsPatch) Empty() bool {
+	return p.Name == nil && p.Description == nil && !p.ClearDescription && p.Removed == nil &&
+		p.Price == nil && !p.ClearPrice && len(p.Attributes) == 0
+}
+
+// Changes drops the fields that already equal the current row.
+func (p GoodsPatch) Changes(current Goods) GoodsPatch {
+	var res GoodsPatch
+	if p.Name != nil && *p.Name != current.Name {
+		res.Name = p.Name
+	}
+	switch {
+	case p.ClearDescription:
+		res.ClearDescription = current.Description != ""
+	case p.Description != nil && *p.Description != current.Description:
+		res.Description = p.Description
+	}
+	if p.Removed != nil && *p.Removed != current.Removed {
+		res.Removed = p.Removed
+	}
+	switch {
+	case p.ClearPrice:
+		res.ClearPrice = current.Price != nil
+	case p.Price != nil:
+		if current.Price == nil || !current.Price.Equal(*p.Price) || *current.Currency != *p.Currency {
+			res.Price, res.Currency = p.Price, p.Currency
+		}
+	}
+	res.Attributes = attributeChanges(p.Attributes, current.Attributes)
+	return res
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoodsPatchChanges(t *testing.T) {
+	name, other := "apple", "pear"
+	description := "fresh"
+	removed := true
+	price, cheaper := decimal.RequireFromString("9.90"), decimal.RequireFromString("5")
+	eur, usd := "EUR", "USD"
+	current := Goods{
+		Name:        name,
+		Description: description,
+		Price:       &price,
+		Currency:    &eur,
+		Attributes:  map[string]interface{}{"color": "red"},
+	}
+	tests := []struct {
+		name  string
+		patch GoodsPatch
+		want  GoodsPatch
+	}{
+		{"empty", GoodsPatch{}, GoodsPatch{}},
+		{"same values", GoodsPatch{Name: &name, Description: &description}, GoodsPatch{}},
+		{"new name", GoodsPatch{Name: &other}, GoodsPatch{Name: &other}},
+		{"removed", GoodsPatch{Removed: &removed}, GoodsPatch{Removed: &removed}},
+		{"clear description", GoodsPatch{ClearDescription: true}, GoodsPatch{ClearDescription: true}},
+		{"same price", GoodsPatch{Price: &price, Currency: &eur}, GoodsPatch{}},
+		{"new price", GoodsPatch{Price: &cheaper, Currency: &eur}, GoodsPatch{Price: &cheaper, Currency: &eur}},
+		{"new currency", GoodsPatch{Price: &price, Currency: &usd}, GoodsPatch{Price: &price, Currency: &usd}},
+		{"clear price", GoodsPatch{ClearPrice: true}, GoodsPatch{ClearPrice: true}},
+		{"same attribute", GoodsPatch{Attributes: map[string]interface{}{"color": "red"}}, GoodsPatch{}},
+		{"drop attribute", GoodsPatch{Attributes: map[string]interface{}{"color": nil, "size": nil}},
+			GoodsPatch{Attributes: map[string]interface{}{"color": nil}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.patch.Changes(current))
+		})
+	}
+}
+
+func TestGoodsPatchChangesEmptyRow(t *testing.T) {
+	patch := GoodsPatch{ClearDescription: true, ClearPrice: true}
+	assert.True(t, patch.Changes(Goods{}).Empty())
+}
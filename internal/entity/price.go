@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// PriceScale is the number of fractional digits stored for a price, enough
+// for every ISO 4217 minor unit.
+const PriceScale = 4
+
+// maxPrice keeps prices within NUMERIC(15, 4).
+var maxPrice = decimal.New(1, 11)
+
+// ValidatePrice checks that price and currency are given together, the price
+// is stored without rounding and the currency is an ISO 4217 code in use.
+func ValidatePrice(price *decimal.Decimal, currency *string) error {
+	if price == nil && currency == nil {
+		return nil
+	}
+	if price == nil || currency == nil {
+		return fmt.Errorf("price and currency must be set together")
+	}
+	if price.IsNegative() || !price.LessThan(maxPrice) {
+		return fmt.Errorf("price must be from 0 to %s", maxPrice)
+	}
+	if !price.Equal(price.Truncate(PriceScale)) {
+		return fmt.Errorf("price must have at most %d fractional digits", PriceScale)
+	}
+	if !validCurrency(*currency) {
+		return fmt.Errorf("currency must be an ISO 4217 code")
+	}
+	return nil
+}
+
+// currencyCodes lists the ISO 4217 codes of the currencies in circulation,
+// fund codes, precious metals and withdrawn currencies are not accepted.
+var currencyCodes = map[string]struct{}{
+	"AED": {}, "AFN": {}, "ALL": {}, "AMD": {}, "AOA": {}, "ARS": {}, "AUD": {}, "AWG": {}, "AZN": {},
+	"BAM": {}, "BBD": {}, "BDT": {}, "BHD": {}, "BIF": {}, "BMD": {}, "BND": {}, "BOB": {}, "BRL": {},
+	"BSD": {}, "BTN": {}, "BWP": {}, "BYN": {}, "BZD": {}, "CAD": {}, "CDF": {}, "CHF": {}, "CLP": {},
+	"CNY": {}, "COP": {}, "CRC": {}, "CUP": {}, "CVE": {}, "CZK": {}, "DJF": {}, "DKK": {}, "DOP": {},
+	"DZD": {}, "EGP": {}, "ERN": {}, "ETB": {}, "EUR": {}, "FJD": {}, "FKP": {}, "GBP": {}, "GEL": {},
+	"GHS": {}, "GIP": {}, "GMD": {}, "GNF": {}, "GTQ": {}, "GYD": {}, "HKD": {}, "HNL": {}, "HTG": {},
+	"HUF": {}, "IDR": {}, "ILS": {}, "INR": {}, "IQD": {}, "IRR": {}, "ISK": {}, "JMD": {}, "JOD": {},
+	"JPY": {}, "KES": {}, "KGS": {}, "KHR": {}, "KMF": {}, "KPW": {}, "KRW": {}, "KWD": {}, "KYD": {},
+	"KZT": {}, "LAK": {}, "LBP": {}, "LKR": {}, "LRD": {}, "LSL": {}, "LYD": {}, "MAD": {}, "MDL": {},
+	"MGA": {}, "MKD": {}, "MMK": {}, "MNT": {}, "MOP": {}, "MRU": {}, "MUR": {}, "MVR": {}, "MWK": {},
+	"MXN": {}, "MYR": {}, "MZN": {}, "NAD": {}, "NGN": {}, "NIO": {}, "NOK": {}, "NPR": {}, "NZD": {},
+	"OMR": {}, "PAB": {}, "PEN": {}, "PGK": {}, "PHP": {}, "PKR": {}, "PLN": {}, "PYG": {}, "QAR": {},
+	"RON": {}, "RSD": {}, "RUB": {}, "RWF": {}, "SAR": {}, "SBD": {}, "SCR": {}, "SDG": {}, "SEK": {},
+	"SGD": {}, "SHP": {}, "SLE": {}, "SOS": {}, "SRD": {}, "SSP": {}, "STN": {}, "SVC": {}, "SYP": {},
+	"SZL": {}, "THB": {}, "TJS": {}, "TMT": {}, "TND": {}, "TOP": {}, "TRY": {}, "TTD": {}, "TWD": {},
+	"TZS": {}, "UAH": {}, "UGX": {}, "USD": {}, "UYU": {}, "UZS": {}, "VED": {}, "VES": {}, "VND": {},
+	"VUV": {}, "WST": {}, "XAF": {}, "XCD": {}, "XCG": {}, "XOF": {}, "XPF": {}, "YER": {}, "ZAR": {},
+	"ZMW": {}, "ZWG": {},
+}
+
+func validCurrency(s string) bool {
+	_, ok := currencyCodes[s]
+	return ok
+}
+
+// PriceStats aggregates live goods of a project priced in one currency.
+type PriceStats struct {
+	Currency string          `json:"currency"`
+	Count    int             `json:"count"`
+	Min      decimal.Decimal `json:"min"`
+	Max      decimal.Decimal `json:"max"`
+	Avg      decimal.Decimal `json:"avg"`
+}
+
+// ProjectSummary describes the live goods of a project, prices in different
+// currencies are never mixed.
+type ProjectSummary struct {
+	ProjectId int          `json:"project_id"`
+	Goods     int          `json:"goods"`
+	Priced    int          `json:"priced"`
+	Prices    []PriceStats `json:"prices"`
+}
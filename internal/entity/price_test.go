@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePrice(t *testing.T) {
+	price := func(s string) *decimal.Decimal {
+		d := decimal.RequireFromString(s)
+		return &d
+	}
+	currency := func(s string) *string { return &s }
+	tests := []struct {
+		name     string
+		price    *decimal.Decimal
+		currency *string
+		wantErr  bool
+	}{
+		{"no price", nil, nil, false},
+		{"valid", price("12.50"), currency("USD"), false},
+		{"zero", price("0"), currency("EUR"), false},
+		{"four digits", price("0.0001"), currency("KWD"), false},
+		{"upper bound", price("99999999999.9999"), currency("JPY"), false},
+		{"price only", price("1"), nil, true},
+		{"currency only", nil, currency("USD"), true},
+		{"negative", price("-1"), currency("USD"), true},
+		{"too large", price("100000000000"), currency("USD"), true},
+		{"five digits", price("0.00001"), currency("USD"), true},
+		{"lower case", price("1"), currency("usd"), true},
+		{"unknown code", price("1"), currency("ABC"), true},
+		{"fund code", price("1"), currency("XXX"), true},
+		{"withdrawn", price("1"), currency("HRK"), true},
+		{"too long", price("1"), currency("USDT"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePrice(tt.price, tt.currency)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+)
+
+type ProjectStatus string
+
+const (
+	ProjectDraft    ProjectStatus = "draft"
+	ProjectActive   ProjectStatus = "active"
+	ProjectPaused   ProjectStatus = "paused"
+	ProjectArchived ProjectStatus = "archived"
+)
+
+func (s ProjectStatus) Valid() bool {
+	switch s {
+	case ProjectDraft, ProjectActive, ProjectPaused, ProjectArchived:
+		return true
+	}
+	return false
+}
+
+// ProjectFilter narrows GET /projects, zero fields are not applied.
+type ProjectFilter struct {
+	Status ProjectStatus
+}
+
+// ErrProjectArchived rejects writes to goods of an archived project.
+var ErrProjectArchived = errors.New("project archived")
+
+// TransitionError is returned for a status change the lifecycle of a project
+// or a good does not allow.
+type TransitionError struct {
+	Entity string
+	From   string
+	To     string
+	Reason string
+}
+
+func (e *TransitionError) Error() string {
+	msg := fmt.Sprintf("%s status can not change from %s to %s", e.Entity, e.From, e.To)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
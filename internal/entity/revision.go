@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"time"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// GoodsRevision is a snapshot of a good taken in the transaction that wrote
+// it, Revision is the version of the good at that point.
+type GoodsRevision struct {
+	Revision     int           `json:"revision"`
+	Action       EventAction   `json:"action"`
+	RevertedFrom *int          `json:"reverted_from,omitempty"`
+	Goods        Goods         `json:"goods"`
+	Changes      []FieldChange `json:"changes"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// FieldChange holds the JSON values of a field before and after a revision.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// revisionMeta are fields that change with every write and say nothing about
+// the content.
+var revisionMeta = map[string]bool{"id": true, "version": true, "created_at": true, "updated_at": true}
+
+// DiffGoods compares two snapshots field by field as they are shown in the
+// API, prev may be nil for the first revision.
+func DiffGoods(prev *Goods, next Goods) ([]FieldChange, error) {
+	before := make(map[string]interface{})
+	if prev != nil {
+		if err := jsonRoundTrip(*prev, &before); err != nil {
+			return nil, err
+		}
+	}
+	var after map[string]interface{}
+	if err := jsonRoundTrip(next, &after); err != nil {
+		return nil, err
+	}
+	res := make([]FieldChange, 0)
+	for field, to := range after {
+		from := before[field]
+		if revisionMeta[field] || reflect.DeepEqual(from, to) {
+			continue
+		}
+		res = append(res, FieldChange{Field: field, From: from, To: to})
+	}
+	for field, from := range before {
+		if _, ok := after[field]; !ok && !revisionMeta[field] {
+			res = append(res, FieldChange{Field: field, From: from})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Field < res[j].Field })
+	return res, nil
+}
+
+func jsonRoundTrip(v interface{}, dst *map[string]interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// RevertPatch returns the changes that bring current back to the revision.
+// Only the content is reverted: name, description, removed, price and
+// attributes. Project, priority, status, window and tags stay as they are.
+func (r GoodsRevision) RevertPatch(current Goods) GoodsPatch {
+	g := r.Goods
+	patch := GoodsPatch{
+		Name:        &g.Name,
+		Description: &g.Description,
+		Removed:     &g.Removed,
+		Attributes:  make(map[string]interface{}, len(g.Attributes)),
+	}
+	if g.Price != nil {
+		patch.Price, patch.Currency = g.Price, g.Currency
+	} else {
+		patch.ClearPrice = true
+	}
+	for key := range current.Attributes {
+		if _, ok := g.Attributes[key]; !ok {
+			patch.Attributes[key] = nil
+		}
+	}
+	for key, val := range g.Attributes {
+		patch.Attributes[key] = val
+	}
+	return patch.Changes(current)
+}
@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffGoods(t *testing.T) {
+	price := decimal.RequireFromString("9.90")
+	eur := "EUR"
+	removedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := Goods{Id: 1, ProjectId: 1, Name: "apple", Status: GoodsActive, Version: 1}
+	with := func(change func(g *Goods)) Goods {
+		g := base
+		change(&g)
+		return g
+	}
+	tests := []struct {
+		name string
+		prev *Goods
+		next Goods
+		want []FieldChange
+	}{
+		{"only meta", &base, with(func(g *Goods) { g.Version = 2; g.UpdatedAt = removedAt }), []FieldChange{}},
+		{"name", &base, with(func(g *Goods) { g.Name = "pear" }),
+			[]FieldChange{{Field: "name", From: "apple", To: "pear"}}},
+		{"price", &base, with(func(g *Goods) { g.Price, g.Currency = &price, &eur }),
+			[]FieldChange{{Field: "currency", To: "EUR"}, {Field: "price", To: "9.9"}}},
+		{"removed", &base, with(func(g *Goods) { g.Removed, g.RemovedAt = true, &removedAt }),
+			[]FieldChange{{Field: "removed", From: false, To: true}, {Field: "removed_at", To: "2026-01-01T00:00:00Z"}}},
+		{"restored", &Goods{Name: "apple", RemovedAt: &removedAt}, Goods{Name: "apple"},
+			[]FieldChange{{Field: "removed_at", From: "2026-01-01T00:00:00Z"}}},
+		{"attribute", &base, with(func(g *Goods) { g.Attributes = map[string]interface{}{"size": 42} }),
+			[]FieldChange{{Field: "attributes", To: map[string]interface{}{"size": 42.0}}}},
+		{"first revision", nil, Goods{Name: "apple", Status: GoodsDraft}, []FieldChange{
+			{Field: "description", To: ""},
+			{Field: "name", To: "apple"},
+			{Field: "priority", To: 0.0},
+			{Field: "project_id", To: 0.0},
+			{Field: "removed", To: false},
+			{Field: "status", To: "draft"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffGoods(tt.prev, tt.next)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRevertPatch(t *testing.T) {
+	price := decimal.RequireFromString("9.90")
+	eur := "EUR"
+	current := Goods{
+		Name:        "pear",
+		Description: "ripe",
+		Priority:    3,
+		Status:      GoodsPaused,
+		Price:       &price,
+		Currency:    &eur,
+		Attributes:  map[string]interface{}{"color": "green", "size": 42.0},
+	}
+	name, description, removed := "apple", "fresh", true
+	tests := []struct {
+		name     string
+		revision Goods
+		want     GoodsPatch
+	}{
+		{"same content", Goods{Name: "pear", Description: "ripe", Priority: 1, Status: GoodsActive, Price: &price,
+			Currency: &eur, Attributes: map[string]interface{}{"color": "green", "size": 42.0}}, GoodsPatch{}},
+		{"content", Goods{Name: name, Description: description, Removed: removed, Price: &price, Currency: &eur,
+			Attributes: map[string]interface{}{"color": "green", "size": 42.0}},
+			GoodsPatch{Name: &name, Description: &description, Removed: &removed}},
+		{"no price", Goods{Name: "pear", Description: "ripe",
+			Attributes: map[string]interface{}{"color": "green", "size": 42.0}}, GoodsPatch{ClearPrice: true}},
+		{"attributes", Goods{Name: "pear", Description: "ripe", Price: &price, Currency: &eur,
+			Attributes: map[string]interface{}{"color": "red"}},
+			GoodsPatch{Attributes: map[string]interface{}{"color": "red", "size": nil}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rev := GoodsRevision{Revision: 1, Goods: tt.revision}
+			assert.Equal(t, tt.want, rev.RevertPatch(current))
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaxGoodsTags limits the tags of one good.
+const MaxGoodsTags = 50
+
+type Tag struct {
+	Id        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ErrTagExists means another tag already has the name.
+var ErrTagExists = errors.New("tag already exists")
+
+// NormalizeTags trims the names, drops empty ones and duplicates and sorts
+// the rest, the order goods carry their tags in.
+func NormalizeTags(names []string) []string {
+	res := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			res = append(res, name)
+		}
+	}
+	slices.Sort(res)
+	return slices.Compact(res)
+}
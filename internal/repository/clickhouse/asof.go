@@ -0,0 +1,119 @@
+package clickHouse
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	queryProjectLastAction = `SELECT argMax(action, event_time)
+	FROM logs.events
+	WHERE entity = 'project' AND entity_id = ? AND event_time <= ?`
+	// queryGoodsAsOf folds the events of every good that ever was in the
+	// project. A field takes the latest non-null value, the project is the
+	// one of the last event, so goods moved out are dropped by HAVING.
+	queryGoodsAsOf = `SELECT entity_id,
+		argMax(project_id, event_time) AS project,
+		countIf(action = 'create') > 0,
+		argMaxIf(name, event_time, name != ''),
+		argMaxIf(description, event_time, description IS NOT NULL),
+		argMaxIf(priority, event_time, priority IS NOT NULL) AS goods_priority,
+		argMaxIf(removed, event_time, removed IS NOT NULL) AS is_removed,
+		argMaxIf(status, event_time, status IS NOT NULL),
+		argMaxIf(active_from, event_time, active_from IS NOT NULL),
+		argMaxIf(active_to, event_time, active_to IS NOT NULL),
+		argMaxIf(price, event_time, price IS NOT NULL),
+		argMaxIf(currency, event_time, currency IS NOT NULL),
+		argMaxIf(created_at, event_time, created_at IS NOT NULL),
+		max(event_time)
+	FROM logs.events
+	WHERE entity = 'good' AND event_time <= ? AND entity_id IN (
+		SELECT entity_id FROM logs.events
+		WHERE entity = 'good' AND event_time <= ? AND (project_id = ? OR from_project_id = ?)
+	)
+	GROUP BY entity_id
+	HAVING project = ? AND (? OR NOT ifNull(is_removed, 0))
+	ORDER BY goods_priority NULLS LAST, entity_id`
+)
+
+// GetProjectGoodsAsOf rebuilds the goods of the project at asOf from the
+// event log. A project deleted by then is not found, one without events by
+// then is returned as unknown.
+func (ch *ClickHouse) GetProjectGoodsAsOf(ctx context.Context, projectId int, asOf time.Time, includeRemoved bool) (*entity.ProjectGoodsAsOf, error) {
+	var lastAction string
+	err := ch.Conn.QueryRow(ctx, queryProjectLastAction, int32(projectId), asOf).Scan(&lastAction)
+	if err != nil {
+		return nil, fmt.Errorf("failed get project as of: %w", err)
+	}
+	if entity.EventAction(lastAction) == entity.Delete {
+		return nil, entity.ErrNotFound
+	}
+
+	id := int32(projectId)
+	rows, err := ch.Conn.Query(ctx, queryGoodsAsOf, asOf, asOf, id, id, id, includeRemoved)
+	if err != nil {
+		return nil, fmt.Errorf("failed get goods as of: %w", err)
+	}
+	defer rows.Close()
+
+	res := &entity.ProjectGoodsAsOf{
+		ProjectId: projectId,
+		AsOf:      asOf,
+		// argMax over no rows gives the default value
+		Known: lastAction != "",
+		Goods: make([]entity.GoodsAsOf, 0),
+	}
+	for rows.Next() {
+		var (
+			item                            entity.GoodsAsOf
+			goodsId, project                int32
+			known                           uint8
+			name                            string
+			priority                        *int32
+			removed                         *uint8
+			activeFrom, activeTo, createdAt *time.Time
+			price                           *decimal.Decimal
+		)
+		err = rows.Scan(
+			&goodsId,
+			&project,
+			&known,
+			&name,
+			&item.Description,
+			&priority,
+			&removed,
+			&item.Status,
+			&activeFrom,
+			&activeTo,
+			&price,
+			&item.Currency,
+			&createdAt,
+			&item.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan goods as of: %w", err)
+		}
+		item.Id = int(goodsId)
+		item.ProjectId = int(project)
+		item.Known = known != 0
+		if name != "" {
+			item.Name = &name
+		}
+		item.Priority = intPtr(priority)
+		if removed != nil {
+			val := *removed != 0
+			item.Removed = &val
+		}
+		item.ActiveFrom, item.ActiveTo, item.CreatedAt = activeFrom, activeTo, createdAt
+		item.Price = price
+		res.Goods = append(res.Goods, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed get goods as of: %w", err)
+	}
+	return res, nil
+}
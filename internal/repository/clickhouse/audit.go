@@ -0,0 +1,162 @@
+package clickHouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+// auditColumns reads event_time, timestamp only has second precision.
+const auditColumns = `event_time, action, entity, entity_id, project_id, from_project_id, name, description,
+	priority, removed, status, from_status, active_from, active_to, price, currency, tags, attributes, created_at, changed_fields,
+	toString(event_id)`
+
+// auditWhere builds the conditions for ? placeholders.
+func auditWhere(f entity.AuditFilter) (string, []any) {
+	conds := []string{"1"}
+	var args []any
+	if f.Entity != "" {
+		conds = append(conds, "entity = ?")
+		args = append(args, f.Entity)
+	}
+	if f.EntityId != nil {
+		conds = append(conds, "entity_id = ?")
+		args = append(args, int32(*f.EntityId))
+	}
+	if f.Action != "" {
+		conds = append(conds, "action = ?")
+		args = append(args, string(f.Action))
+	}
+	if f.Field != "" {
+		conds = append(conds, "has(changed_fields, ?)")
+		args = append(args, f.Field)
+	}
+	if f.ProjectId != nil {
+		conds = append(conds, "(project_id = ? OR from_project_id = ?)")
+		args = append(args, int32(*f.ProjectId), int32(*f.ProjectId))
+	}
+	if f.From != nil {
+		conds = append(conds, "event_time >= ?")
+		args = append(args, *f.From)
+	}
+	if f.To != nil {
+		conds = append(conds, "event_time < ?")
+		args = append(args, *f.To)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// GetEvents reads the event log newest first. The page continues after the
+// (event_time, event_id) of the cursor, so events arriving meanwhile do not
+// shift it.
+func (ch *ClickHouse) GetEvents(ctx context.Context, filter entity.AuditFilter, page entity.Page) (*entity.AuditResponse, error) {
+	where, args := auditWhere(filter)
+
+	var total uint64
+	err := ch.Conn.QueryRow(ctx, `SELECT count() FROM logs.events WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed count events: %w", err)
+	}
+
+	if page.After != nil {
+		if page.After.EventTime == nil || page.After.EventId == "" {
+			return nil, entity.ErrInvalidCursor
+		}
+		// the driver binds time.Time with second precision
+		where += " AND (event_time, event_id) < (fromUnixTimestamp64Micro(?), toUUID(?))"
+		args = append(args, page.After.EventTime.UnixMicro(), page.After.EventId)
+	}
+	query := fmt.Sprintf(`SELECT %s FROM logs.events WHERE %s
+	ORDER BY event_time DESC, event_id DESC
+	LIMIT %d`, auditColumns, where, page.Limit+1)
+	rows, err := ch.Conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed get events: %w", err)
+	}
+	defer rows.Close()
+
+	var lastId string
+	res := &entity.AuditResponse{
+		Events: make([]entity.AuditEvent, 0, page.Limit),
+		Meta:   entity.PageMeta{Limit: page.Limit, Total: int(total)},
+	}
+	for rows.Next() {
+		var (
+			event                           entity.AuditEvent
+			action                          string
+			entityId, projectId             int32
+			fromProject, priority           *int32
+			removed                         *uint8
+			activeFrom, activeTo, createdAt *time.Time
+			price                           *decimal.Decimal
+			attributes                      *string
+			eventId                         string
+		)
+		err = rows.Scan(
+			&event.Timestamp,
+			&action,
+			&event.Entity,
+			&entityId,
+			&projectId,
+			&fromProject,
+			&event.Name,
+			&event.Description,
+			&priority,
+			&removed,
+			&event.Status,
+			&event.FromStatus,
+			&activeFrom,
+			&activeTo,
+			&price,
+			&event.Currency,
+			&event.Tags,
+			&attributes,
+			&createdAt,
+			&event.ChangedFields,
+			&eventId,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan event: %w", err)
+		}
+		event.Action = entity.EventAction(action)
+		event.EntityId = int(entityId)
+		event.ProjectId = int(projectId)
+		event.FromProjectId = intPtr(fromProject)
+		event.Priority = intPtr(priority)
+		if removed != nil {
+			val := *removed != 0
+			event.Removed = &val
+		}
+		event.ActiveFrom, event.ActiveTo, event.CreatedAt = activeFrom, activeTo, createdAt
+		event.Price = price
+		if attributes != nil {
+			event.Attributes = json.RawMessage(*attributes)
+		}
+		if len(res.Events) < page.Limit {
+			lastId = eventId
+		}
+		res.Events = append(res.Events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed get events: %w", err)
+	}
+	if len(res.Events) > page.Limit {
+		res.Events = res.Events[:page.Limit]
+		last := res.Events[page.Limit-1].Timestamp
+		res.Meta.NextCursor = entity.Cursor{EventTime: &last, EventId: lastId}.Encode()
+	}
+	return res, nil
+}
+
+func intPtr(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	res := int(*v)
+	return &res
+}
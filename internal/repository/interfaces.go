@@ -2,23 +2,55 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/paxaf/HezzlTest/internal/entity"
 )
 
 type Postgres interface {
-	GetItemsByName(ctx context.Context, name string) ([]entity.Goods, error)
-	GetItemsByProject(ctx context.Context, projectId int) ([]entity.Goods, error)
+	GetItemsByName(ctx context.Context, name string, includeRemoved bool, tag string, page entity.Page) (*entity.GoodsResponse, error)
+	GetItemsByProject(ctx context.Context, projectId int, includeRemoved bool, tag string, page entity.Page) (*entity.GoodsResponse, error)
 	GetItem(ctx context.Context, goodsId int) (*entity.Goods, error)
-	GetAllItems(ctx context.Context) ([]entity.Goods, error)
+	SearchItems(ctx context.Context, query string, includeRemoved bool, page entity.Page) (*entity.GoodsSearchResponse, error)
+	GetAllItems(ctx context.Context, filter entity.GoodsFilter, page entity.Page) (*entity.GoodsResponse, error)
+	StreamItems(ctx context.Context, filter entity.GoodsFilter, fn func(entity.Goods) error) error
 	CreateItem(ctx context.Context, item *entity.Goods) error
-	UpdateItem(ctx context.Context, item *entity.Goods) error
-	DeleteItem(ctx context.Context, id int) error
-	DeleteProject(ctx context.Context, id int) error
+	CreateItems(ctx context.Context, items []entity.Goods) ([]entity.Goods, error)
+	UpsertItems(ctx context.Context, projectId int, items []entity.Goods) ([]entity.Goods, []entity.GoodsChange, error)
+	UpdateItem(ctx context.Context, item *entity.Goods) (entity.Goods, error)
+	PatchItem(ctx context.Context, item *entity.Goods, patch entity.GoodsPatch) (entity.Goods, entity.GoodsPatch, error)
+	GetItemRevisions(ctx context.Context, id int) ([]entity.GoodsRevision, error)
+	RevertItem(ctx context.Context, item *entity.Goods, revision int) (entity.Goods, entity.GoodsPatch, error)
+	DeleteItem(ctx context.Context, id int) (*entity.Goods, error)
+	UpdateItems(ctx context.Context, sel entity.GoodsSelector, patch entity.GoodsPatch) ([]entity.GoodsChange, error)
+	DeleteItems(ctx context.Context, sel entity.GoodsSelector) ([]entity.Goods, error)
+	RestoreItem(ctx context.Context, id int) (*entity.Goods, error)
+	ReprioritizeItem(ctx context.Context, id int, priority int) ([]entity.GoodsChange, error)
+	MoveItem(ctx context.Context, id int, projectId int, priority int) (*entity.MoveResult, error)
+	SetItemStatus(ctx context.Context, id int, from, to entity.GoodsStatus) (*entity.Goods, error)
+	SetItemWindow(ctx context.Context, id int, from, to *time.Time, now time.Time) (*entity.GoodsChange, error)
+	ActivateDueItems(ctx context.Context, now time.Time) ([]entity.WindowChange, error)
+	ExpireDueItems(ctx context.Context, now time.Time) ([]entity.WindowChange, error)
+	SetItemTags(ctx context.Context, id int, tags []string) (*entity.GoodsChange, error)
+	GetTags(ctx context.Context) ([]entity.Tag, error)
+	GetTag(ctx context.Context, id int) (*entity.Tag, error)
+	CreateTag(ctx context.Context, tag *entity.Tag) error
+	RenameTag(ctx context.Context, tag *entity.Tag) ([]entity.GoodsChange, error)
+	DeleteTag(ctx context.Context, id int) ([]entity.GoodsChange, error)
+	DeleteProject(ctx context.Context, id int, force bool) (*entity.Project, []entity.Goods, error)
+	CloneProject(ctx context.Context, id int, name string) (*entity.Project, []entity.Goods, error)
 	AddProject(ctx context.Context, item *entity.Project) error
 	UpdateProject(ctx context.Context, item *entity.Project) error
 	GetProject(ctx context.Context, id int) (*entity.Project, error)
-	GetProjects(ctx context.Context) ([]entity.Project, error)
+	GetProjectSummary(ctx context.Context, id int) (*entity.ProjectSummary, error)
+	GetProjects(ctx context.Context, filter entity.ProjectFilter, page entity.Page) (*entity.ProjectResponse, error)
+	SetProjectStatus(ctx context.Context, id int, from, to entity.ProjectStatus) (*entity.Project, error)
+	SuggestItems(ctx context.Context, query string, projectId int, limit int) ([]entity.Suggestion, error)
+	SuggestProjects(ctx context.Context, query string, limit int) ([]entity.Suggestion, error)
+	CreateImportJob(ctx context.Context, job *entity.ImportJob) error
+	UpdateImportJob(ctx context.Context, job *entity.ImportJob) error
+	GetImportJob(ctx context.Context, id int) (*entity.ImportJob, error)
+	FailUnfinishedImportJobs(ctx context.Context, reason string) (int, error)
 }
 
 type Redis interface {
@@ -27,29 +59,46 @@ type Redis interface {
 	RedisSetItem(key string, item interface{}) error
 	RedisGetProjects(key string) ([]entity.Project, error)
 	RedisGetProject(key string) (*entity.Project, error)
+	RedisGetGoodsPage(key string) (*entity.GoodsResponse, error)
+	RedisGetProjectsPage(key string) (*entity.ProjectResponse, error)
+	RedisGetSearchPage(key string) (*entity.GoodsSearchResponse, error)
 	CleanCache() error
+	AutocompleteAdd(scope entity.SuggestScope, projectId int, item entity.Suggestion) error
+	AutocompleteRemove(scope entity.SuggestScope, id int) error
+	AutocompleteRemoveProject(projectId int) error
+	AutocompleteSearch(scope entity.SuggestScope, prefix string, projectId int, limit int) ([]entity.Suggestion, error)
+	AutocompleteClear(scope entity.SuggestScope) error
 }
 
 type Nats interface {
 	LogEvent(event entity.Event)
 }
 
+// Audit reads back the events the worker stored in ClickHouse.
+type Audit interface {
+	GetEvents(ctx context.Context, filter entity.AuditFilter, page entity.Page) (*entity.AuditResponse, error)
+	GetProjectGoodsAsOf(ctx context.Context, projectId int, asOf time.Time, includeRemoved bool) (*entity.ProjectGoodsAsOf, error)
+}
+
 type Repository interface {
 	Postgres
 	Redis
 	Nats
+	Audit
 }
 
 type Repo struct {
 	Redis
 	Postgres
 	Nats
+	Audit
 }
 
-func New(redis Redis, pgpool Postgres, nats Nats) *Repo {
+func New(redis Redis, pgpool Postgres, nats Nats, audit Audit) *Repo {
 	return &Repo{
 		Redis:    redis,
 		Postgres: pgpool,
 		Nats:     nats,
+		Audit:    audit,
 	}
 }
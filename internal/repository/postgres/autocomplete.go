@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+const (
+	querySuggestGoods = `SELECT id, name FROM GOODS
+	WHERE NOT removed AND ($2 = 0 OR project_id = $2) AND $1 <% name
+	ORDER BY word_similarity($1, name) DESC, id
+	LIMIT $3`
+	querySuggestProjects = `SELECT id, name FROM projects
+	WHERE $1 <% name
+	ORDER BY word_similarity($1, name) DESC, id
+	LIMIT $2`
+)
+
+// SuggestItems matches names by trigram word similarity, it backs the redis
+// prefix index when the query has a typo or the index is not warmed up yet.
+func (r *PgPool) SuggestItems(ctx context.Context, query string, projectId int, limit int) ([]entity.Suggestion, error) {
+	rows, err := r.db.Query(ctx, querySuggestGoods, query, projectId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed suggest goods: %w", err)
+	}
+	return collectSuggestions(rows, limit)
+}
+
+func (r *PgPool) SuggestProjects(ctx context.Context, query string, limit int) ([]entity.Suggestion, error) {
+	rows, err := r.db.Query(ctx, querySuggestProjects, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed suggest projects: %w", err)
+	}
+	return collectSuggestions(rows, limit)
+}
+
+func collectSuggestions(rows pgx.Rows, limit int) ([]entity.Suggestion, error) {
+	defer rows.Close()
+	res := make([]entity.Suggestion, 0, limit)
+	for rows.Next() {
+		var val entity.Suggestion
+		if err := rows.Scan(&val.Id, &val.Name); err != nil {
+			return nil, fmt.Errorf("failed parse into sturct: %w", err)
+		}
+		res = append(res, val)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed collect suggestions: %w", err)
+	}
+	return res, nil
+}
@@ -2,30 +2,82 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/paxaf/HezzlTest/internal/entity"
 )
 
 const (
-	queryGetItemsByProject = `SELECT id, project_id, name, description, priority, removed, created_at
-	FROM GOODS
-	WHERE project_id = $1`
-	queryGetItem = `SELECT id, project_id, name, description, priority, removed, created_at
+	// goodsColumns reads a description cleared by a merge patch as empty
+	goodsColumns = `id, project_id, name, COALESCE(description, '') AS description, priority, removed, removed_at, status, active_from, active_to,
+	price, currency, version, created_at, updated_at, attributes, ` + goodsTags
+	// goodsTags selects the sorted tag names of the goods row in the outer query
+	goodsTags = `ARRAY(SELECT t.name FROM goods_tags gt JOIN tags t ON t.id = gt.tag_id
+	WHERE gt.goods_id = goods.id ORDER BY t.name) AS tags`
+	// bumpVersion is appended to every UPDATE of goods
+	bumpVersion = `version = version + 1, updated_at = NOW()`
+
+	queryGetItem = `SELECT ` + goodsColumns + `
 	FROM GOODS
 	WHERE id = $1`
-	queryGetItemsByName = `SELECT id, project_id, name, description, priority, removed, created_at
-	FROM GOODS
-	WHERE name ILIKE '%' || $1 || '%'`
-	queryGetAllItems = `SELECT id, project_id, name, description, priority, removed, created_at
-	FROM GOODS`
-	queryLockGoods  = `LOCK TABLE goods IN ACCESS EXCLUSIVE MODE`
-	queryCreateItem = `INSERT INTO GOODS (project_id, name, description, priority) 
-	VALUES ($1, $2, $3, (SELECT COALESCE(MAX(priority), 0) + 1 FROM GOODS))`
-	queryUpdateItem = `UPDATE GOODS SET name = $1, description = $2, priority = $3, removed = $4 WHERE id = $5`
-	queryDeleteItem = `DELETE FROM GOODS WHERE id = $1`
+	whereItemsByProject      = `project_id = $1 AND ($2 OR NOT removed)`
+	whereItemsByName         = `name ILIKE '%' || $1 || '%' AND ($2 OR NOT removed)`
+	whereAllItems            = `($1 OR NOT removed)`
+	queryLockProjectPriority = `SELECT pg_advisory_xact_lock(hashtext('goods_priority'), $1)`
+	queryCreateItem          = `INSERT INTO GOODS (project_id, name, description, status, active_from, active_to, price, currency,
+	attributes, priority)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, (SELECT COALESCE(MAX(priority), 0) + 1 FROM GOODS WHERE project_id = $1))
+	RETURNING ` + goodsColumns
+	// queryUpdateItem returns the updated row followed by the row as it was,
+	// both CTEs see the table before the update
+	queryUpdateItem = `WITH prev AS (SELECT ` + goodsColumns + ` FROM GOODS WHERE id = $5 FOR UPDATE),
+	upd AS (UPDATE GOODS SET name = $1, description = $2, priority = $3, removed = $4,
+	removed_at = CASE WHEN $4 THEN COALESCE(removed_at, NOW()) END, attributes = COALESCE($7, attributes),
+	price = COALESCE($8, price), currency = COALESCE($9, currency), ` + bumpVersion + `
+	WHERE id = $5 AND ($6 = 0 OR version = $6)
+	RETURNING ` + goodsColumns + `)
+	SELECT upd.*, prev.* FROM upd JOIN prev ON prev.id = upd.id`
+	queryDeleteItem = `UPDATE GOODS SET removed = true, removed_at = NOW(), ` + bumpVersion + `
+	WHERE id = $1 AND NOT removed
+	RETURNING ` + goodsColumns
+	queryRestoreItem = `UPDATE GOODS SET removed = false, removed_at = NULL, ` + bumpVersion + `
+	WHERE id = $1 AND removed
+	RETURNING ` + goodsColumns
+	queryGetItemProject = `SELECT project_id FROM GOODS WHERE id = $1`
+	queryGetItemLocked  = queryGetItem + ` FOR UPDATE`
+	// the shift queries return the rows like queryUpdateItem, see collectChanges
+	queryShiftPriorities = `WITH prev AS (SELECT ` + goodsColumns + ` FROM GOODS
+	WHERE project_id = $1 AND id <> $2 AND priority >= $3 FOR UPDATE),
+	upd AS (UPDATE GOODS SET priority = priority + 1, ` + bumpVersion + `
+	WHERE id IN (SELECT id FROM prev)
+	RETURNING ` + goodsColumns + `)
+	SELECT upd.*, prev.* FROM upd JOIN prev ON prev.id = upd.id`
+	// queryShiftBetween moves the goods between the old priority $3 and the
+	// new one $4 by one slot towards the old one, the rest are not touched
+	queryShiftBetween = `WITH prev AS (SELECT ` + goodsColumns + ` FROM GOODS
+	WHERE project_id = $1 AND id <> $2 AND priority BETWEEN LEAST($3, $4) AND GREATEST($3, $4) FOR UPDATE),
+	upd AS (UPDATE GOODS SET priority = priority + CASE WHEN $4 < $3 THEN 1 ELSE -1 END, ` + bumpVersion + `
+	WHERE id IN (SELECT id FROM prev)
+	RETURNING ` + goodsColumns + `)
+	SELECT upd.*, prev.* FROM upd JOIN prev ON prev.id = upd.id`
+	querySetPriority = `UPDATE GOODS SET priority = $1, ` + bumpVersion + ` WHERE id = $2
+	RETURNING ` + goodsColumns
+	queryGetItemPlace  = `SELECT project_id, priority FROM GOODS WHERE id = $1`
+	queryProjectExists = `SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1)`
+	queryCloseGap      = `WITH prev AS (SELECT ` + goodsColumns + ` FROM GOODS
+	WHERE project_id = $1 AND id <> $2 AND priority > $3 FOR UPDATE),
+	upd AS (UPDATE GOODS SET priority = priority - 1, ` + bumpVersion + `
+	WHERE id IN (SELECT id FROM prev)
+	RETURNING ` + goodsColumns + `)
+	SELECT upd.*, prev.* FROM upd JOIN prev ON prev.id = upd.id`
+	queryMaxPriorityWithout = `SELECT COALESCE(MAX(priority), 0) FROM GOODS WHERE project_id = $1 AND id <> $2`
+	queryMoveItem           = `UPDATE GOODS SET project_id = $1, priority = $2, ` + bumpVersion + ` WHERE id = $3
+	RETURNING ` + goodsColumns
 )
 
 func execTx(ctx context.Context, tx pgx.Tx, errp *error) {
@@ -42,105 +94,209 @@ func execTx(ctx context.Context, tx pgx.Tx, errp *error) {
 	}
 }
 
-func (r *PgPool) GetItemsByProject(ctx context.Context, projectId int) ([]entity.Goods, error) {
-	rows, err := r.db.Query(ctx, queryGetItemsByProject, projectId)
+// beginSnapshot starts a read only transaction, so that a count and the page
+// read after it see the same rows.
+func (r *PgPool) beginSnapshot(ctx context.Context) (pgx.Tx, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed get goods by project: %w", err)
+		return nil, fmt.Errorf("failed begin tx: %w", err)
 	}
-	defer rows.Close()
-	var res []entity.Goods
-	for rows.Next() {
-		var item entity.Goods
-		err = rows.Scan(
-			&item.Id,
-			&item.ProjectId,
-			&item.Name,
-			&item.Description,
-			&item.Priority,
-			&item.Removed,
-			&item.CreatedAt,
-		)
+	return tx, nil
+}
+
+// lockItemProject takes the priority locks of the project the good is in and
+// of the extra projects, in ascending order so that writers locking two
+// projects cannot deadlock. The project is read again once locked and a good
+// moved in the meantime is followed to its new project. Callers run at read
+// committed, so the statements after the lock see the rows as they are.
+func lockItemProject(ctx context.Context, tx pgx.Tx, id int, extra ...int) (int, error) {
+	locked := make(map[int]bool, len(extra)+1)
+	for {
+		var projectId int
+		err := tx.QueryRow(ctx, queryGetItemProject, id).Scan(&projectId)
 		if err != nil {
-			return nil, fmt.Errorf("failed parse into sturct: %w", err)
+			if errors.Is(err, pgx.ErrNoRows) {
+				err = entity.ErrNotFound
+			}
+			return 0, err
+		}
+		if locked[projectId] {
+			return projectId, nil
+		}
+		err = lockProjects(ctx, tx, locked, append([]int{projectId}, extra...))
+		if err != nil {
+			return 0, err
 		}
-		res = append(res, item)
 	}
-	return res, nil
 }
 
-func (r *PgPool) GetItem(ctx context.Context, goodsId int) (*entity.Goods, error) {
-	row := r.db.QueryRow(ctx, queryGetItem, goodsId)
-	var item entity.Goods
-	err := row.Scan(
+// lockProjects takes the priority locks of the projects not locked yet, in
+// ascending order.
+func lockProjects(ctx context.Context, tx pgx.Tx, locked map[int]bool, projects []int) error {
+	sort.Ints(projects)
+	for _, projectId := range projects {
+		if locked[projectId] {
+			continue
+		}
+		_, err := tx.Exec(ctx, queryLockProjectPriority, projectId)
+		if err != nil {
+			return fmt.Errorf("failed while locking project priority: %w", err)
+		}
+		locked[projectId] = true
+	}
+	return nil
+}
+
+// goodsFields returns the scan destinations for goodsColumns, queries
+// selecting extra columns after them append their own.
+func goodsFields(item *entity.Goods) []any {
+	return []any{
 		&item.Id,
 		&item.ProjectId,
 		&item.Name,
 		&item.Description,
 		&item.Priority,
 		&item.Removed,
+		&item.RemovedAt,
+		&item.Status,
+		&item.ActiveFrom,
+		&item.ActiveTo,
+		numericScanner{&item.Price},
+		&item.Currency,
+		&item.Version,
 		&item.CreatedAt,
-	)
-	if err != nil {
-		return nil, entity.ErrNotFound
+		&item.UpdatedAt,
+		&item.Attributes,
+		&item.Tags,
 	}
-	return &item, nil
 }
 
-func (r *PgPool) GetItemsByName(ctx context.Context, name string) ([]entity.Goods, error) {
-	rows, err := r.db.Query(ctx, queryGetItemsByName, name)
-	if err != nil {
-		return nil, fmt.Errorf("failed get goods by name: %w", err)
-	}
+func scanGoods(row pgx.Row) (entity.Goods, error) {
+	var item entity.Goods
+	err := row.Scan(goodsFields(&item)...)
+	return item, err
+}
+
+func collectGoods(rows pgx.Rows) ([]entity.Goods, error) {
 	defer rows.Close()
 	var res []entity.Goods
 	for rows.Next() {
-		var item entity.Goods
-		err = rows.Scan(
-			&item.Id,
-			&item.ProjectId,
-			&item.Name,
-			&item.Description,
-			&item.Priority,
-			&item.Removed,
-			&item.CreatedAt,
-		)
+		item, err := scanGoods(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed parse into sturct: %w", err)
 		}
 		res = append(res, item)
 	}
-	return res, nil
+	return res, rows.Err()
 }
 
-func (r *PgPool) GetAllItems(ctx context.Context) ([]entity.Goods, error) {
-	rows, err := r.db.Query(ctx, queryGetAllItems)
-	if err != nil {
-		return nil, fmt.Errorf("failed get goods by project: %w", err)
-	}
+// collectChanges scans the rows of queries returning the updated row followed
+// by the row as it was, like queryUpdateItem.
+func collectChanges(rows pgx.Rows) ([]entity.GoodsChange, error) {
 	defer rows.Close()
-	var res []entity.Goods
+	var res []entity.GoodsChange
 	for rows.Next() {
-		var item entity.Goods
-		err = rows.Scan(
-			&item.Id,
-			&item.ProjectId,
-			&item.Name,
-			&item.Description,
-			&item.Priority,
-			&item.Removed,
-			&item.CreatedAt,
-		)
+		var change entity.GoodsChange
+		err := rows.Scan(append(goodsFields(&change.Item), goodsFields(&change.Prev)...)...)
 		if err != nil {
 			return nil, fmt.Errorf("failed parse into sturct: %w", err)
 		}
-		res = append(res, item)
+		res = append(res, change)
 	}
+	return res, rows.Err()
+}
+
+func (r *PgPool) GetItemsByProject(ctx context.Context, projectId int, includeRemoved bool, tag string, page entity.Page) (*entity.GoodsResponse, error) {
+	b := newSQLBuilder(projectId, includeRemoved)
+	b.where(whereItemsByProject)
+	whereTag(b, tag)
+	res, err := r.listGoods(ctx, b, entity.DefaultGoodsSort, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed get goods by project: %w", err)
+	}
+	return res, nil
+}
+
+func (r *PgPool) GetItem(ctx context.Context, goodsId int) (*entity.Goods, error) {
+	item, err := scanGoods(r.db.QueryRow(ctx, queryGetItem, goodsId))
+	if err != nil {
+		return nil, entity.ErrNotFound
+	}
+	return &item, nil
+}
+
+func (r *PgPool) GetItemsByName(ctx context.Context, name string, includeRemoved bool, tag string, page entity.Page) (*entity.GoodsResponse, error) {
+	b := newSQLBuilder(name, includeRemoved)
+	b.where(whereItemsByName)
+	whereTag(b, tag)
+	res, err := r.listGoods(ctx, b, entity.DefaultGoodsSort, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed get goods by name: %w", err)
+	}
+	return res, nil
+}
+
+func (r *PgPool) GetAllItems(ctx context.Context, filter entity.GoodsFilter, page entity.Page) (*entity.GoodsResponse, error) {
+	res, err := r.listGoods(ctx, goodsFilterWhere(filter), filter.OrderBy(), page)
+	if err != nil {
+		return nil, fmt.Errorf("failed get all goods: %w", err)
+	}
+	return res, nil
+}
+
+// listGoods returns one keyset page of goods matching the builder conditions
+// in the given order, sort must end with a unique column.
+func (r *PgPool) listGoods(ctx context.Context, b *sqlBuilder, sort []entity.GoodsSort, page entity.Page) (*entity.GoodsResponse, error) {
+	tx, err := r.beginSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	var total int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM GOODS WHERE `+b.whereClause(), b.args...).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed count goods: %w", err)
+	}
+
+	err = goodsKeyset(b, sort, page.After)
+	if err != nil {
+		return nil, err
+	}
+	where := b.whereClause()
+	limit := b.arg(page.Limit + 1)
+	query := fmt.Sprintf(`SELECT %s FROM GOODS WHERE %s ORDER BY %s LIMIT %s`,
+		goodsColumns, where, goodsOrderBy(sort), limit)
+	rows, err := tx.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	items, err := collectGoods(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &entity.GoodsResponse{
+		Goods: make([]entity.Goods, 0, len(items)),
+		Meta:  entity.PageMeta{Limit: page.Limit, Total: total},
+	}
+	if len(items) > page.Limit {
+		items = items[:page.Limit]
+		res.Meta.NextCursor = entity.NewGoodsCursor(items[len(items)-1], sort).Encode()
+	}
+	res.Goods = append(res.Goods, items...)
 	return res, nil
 }
 
+// CreateItem serializes writers of one project with an advisory lock instead of
+// locking the whole table, so read committed is enough to see the latest MAX(priority).
 func (r *PgPool) CreateItem(ctx context.Context, item *entity.Goods) error {
 	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
-		IsoLevel: pgx.Serializable,
+		IsoLevel: pgx.ReadCommitted,
 	})
 	if err != nil {
 		return fmt.Errorf("failed begin tx: %w", err)
@@ -148,74 +304,284 @@ func (r *PgPool) CreateItem(ctx context.Context, item *entity.Goods) error {
 
 	defer execTx(ctx, tx, &err)
 
-	_, err = tx.Exec(ctx, queryLockGoods)
+	_, err = tx.Exec(ctx, queryLockProjectPriority, item.ProjectId)
 	if err != nil {
-		return fmt.Errorf("failed while locking goods: %w", err)
+		return fmt.Errorf("failed while locking project priority: %w", err)
 	}
-	_, err = tx.Exec(ctx, queryCreateItem,
+	err = checkWritable(ctx, tx, item.ProjectId)
+	if err != nil {
+		return fmt.Errorf("failed create item: %w", err)
+	}
+	created, err := scanGoods(tx.QueryRow(ctx, queryCreateItem,
 		item.ProjectId,
 		item.Name,
 		item.Description,
-	)
+		item.InitialStatus(time.Now()),
+		utcTime(item.ActiveFrom),
+		utcTime(item.ActiveTo),
+		numericArg(item.Price),
+		item.Currency,
+		attributesOrEmpty(item.Attributes),
+	))
+	if err == nil {
+		err = addRevision(ctx, tx, entity.Create, created, nil)
+	}
 	if err != nil {
 		return fmt.Errorf("failed create item: %w", err)
 	}
+	*item = created
 	return nil
 }
 
-func (r *PgPool) UpdateItem(ctx context.Context, item *entity.Goods) error {
+// UpdateItem applies the update only while the row still has item.Version,
+// zero skips the check. On a mismatch item is overwritten with the current row.
+// The good is returned as it was before the update.
+func (r *PgPool) UpdateItem(ctx context.Context, item *entity.Goods) (entity.Goods, error) {
 	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
-		IsoLevel: pgx.Serializable,
+		IsoLevel: pgx.ReadCommitted,
 	})
 	if err != nil {
-		return fmt.Errorf("failed begin tx: %w", err)
+		return entity.Goods{}, fmt.Errorf("failed begin tx: %w", err)
 	}
 
 	defer execTx(ctx, tx, &err)
 
-	_, err = tx.Exec(ctx, queryLockGoods)
+	_, err = lockItemProject(ctx, tx, item.Id)
 	if err != nil {
-		return fmt.Errorf("failed while locking goods: %w", err)
+		return entity.Goods{}, fmt.Errorf("failed update item: %w", err)
 	}
 
-	res, err := tx.Exec(ctx, queryUpdateItem,
+	var updated, prev entity.Goods
+	err = tx.QueryRow(ctx, queryUpdateItem,
 		item.Name,
 		item.Description,
 		item.Priority,
 		item.Removed,
 		item.Id,
-	)
+		item.Version,
+		nullableAttributes(item.Attributes),
+		numericArg(item.Price),
+		item.Currency,
+	).Scan(append(goodsFields(&updated), goodsFields(&prev)...)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		updated, err = scanGoods(tx.QueryRow(ctx, queryGetItem, item.Id))
+		if err == nil {
+			*item = updated
+			err = entity.ErrVersionConflict
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrNotFound
+		}
+	}
+	if err == nil {
+		err = checkWritable(ctx, tx, updated.ProjectId)
+	}
+	if err == nil {
+		err = addRevision(ctx, tx, entity.Update, updated, nil)
+	}
+	if err != nil {
+		return entity.Goods{}, fmt.Errorf("failed update item: %w", err)
+	}
+	*item = updated
+	return prev, nil
+}
+
+func (r *PgPool) DeleteItem(ctx context.Context, id int) (*entity.Goods, error) {
+	return r.setRemoved(ctx, queryDeleteItem, entity.Delete, id)
+}
+
+func (r *PgPool) RestoreItem(ctx context.Context, id int) (*entity.Goods, error) {
+	return r.setRemoved(ctx, queryRestoreItem, entity.Restore, id)
+}
+
+func (r *PgPool) setRemoved(ctx context.Context, query string, action entity.EventAction, id int) (*entity.Goods, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.ReadCommitted,
+	})
 	if err != nil {
-		return fmt.Errorf("failed update item: %w", err)
+		return nil, fmt.Errorf("failed begin tx: %w", err)
 	}
-	if res.RowsAffected() == 0 {
-		err = entity.ErrNotFound
-		return fmt.Errorf("failed update item: %w", err)
+
+	defer execTx(ctx, tx, &err)
+
+	_, err = lockItemProject(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed set removed flag: %w", err)
 	}
-	return nil
+	item, err := scanGoods(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed set removed flag: %w", err)
+	}
+	err = checkWritable(ctx, tx, item.ProjectId)
+	if err == nil {
+		err = addRevision(ctx, tx, action, item, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed set removed flag: %w", err)
+	}
+	return &item, nil
 }
 
-func (r *PgPool) DeleteItem(ctx context.Context, id int) error {
+// ReprioritizeItem moves the good inside its project, only the goods between
+// the old and the new slot shift by one, so priorities stay contiguous. A
+// priority past the end puts the good last.
+func (r *PgPool) ReprioritizeItem(ctx context.Context, id int, priority int) ([]entity.GoodsChange, error) {
 	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
-		IsoLevel: pgx.Serializable,
+		IsoLevel: pgx.ReadCommitted,
 	})
 	if err != nil {
-		return fmt.Errorf("failed begin tx: %w", err)
+		return nil, fmt.Errorf("failed begin tx: %w", err)
 	}
 
 	defer execTx(ctx, tx, &err)
 
-	_, err = tx.Exec(ctx, queryLockGoods)
+	projectId, err := lockItemProject(ctx, tx, id)
 	if err != nil {
-		return fmt.Errorf("failed while locking goods: %w", err)
+		return nil, fmt.Errorf("failed reprioritize item: %w", err)
 	}
-	res, err := tx.Exec(ctx, queryDeleteItem, id)
+	current, err := currentItem(ctx, tx, &entity.Goods{Id: id})
 	if err != nil {
-		return fmt.Errorf("failed delete item: %w", err)
+		return nil, fmt.Errorf("failed reprioritize item: %w", err)
 	}
-	if res.RowsAffected() == 0 {
-		err = entity.ErrNotFound
-		return fmt.Errorf("failed delete item: %w", err)
+	var maxPriority int
+	err = tx.QueryRow(ctx, queryMaxPriorityWithout, projectId, id).Scan(&maxPriority)
+	if err != nil {
+		return nil, fmt.Errorf("failed get max priority: %w", err)
 	}
-	return nil
+	// the good keeps its slot in the project, so the last one is taken
+	priority = min(priority, max(maxPriority, current.Priority))
+	if priority == current.Priority {
+		return []entity.GoodsChange{{Prev: current, Item: current}}, nil
+	}
+
+	rows, err := tx.Query(ctx, queryShiftBetween, projectId, id, current.Priority, priority)
+	if err != nil {
+		return nil, fmt.Errorf("failed shift priorities: %w", err)
+	}
+	res, err := collectChanges(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed shift priorities: %w", err)
+	}
+
+	item, err := scanGoods(tx.QueryRow(ctx, querySetPriority, priority, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed set priority: %w", err)
+	}
+	res = append([]entity.GoodsChange{{Prev: current, Item: item}}, res...)
+	err = addRevisions(ctx, tx, entity.Update, entity.ChangedGoods(res))
+	if err != nil {
+		return nil, fmt.Errorf("failed reprioritize item: %w", err)
+	}
+	return res, nil
+}
+
+// MoveItem closes the gap the good leaves in its project and opens one at
+// priority in the target project, a zero or too large priority appends the
+// good to the end. Moving inside the same project works the same way.
+func (r *PgPool) MoveItem(ctx context.Context, id int, projectId int, priority int) (*entity.MoveResult, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.ReadCommitted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	_, err = lockItemProject(ctx, tx, id, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("failed move item: %w", err)
+	}
+
+	var fromProject, fromPriority int
+	err = tx.QueryRow(ctx, queryGetItemPlace, id).Scan(&fromProject, &fromPriority)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed move item: %w", err)
+	}
+	var exists bool
+	err = tx.QueryRow(ctx, queryProjectExists, projectId).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed check project: %w", err)
+	}
+	if !exists {
+		err = entity.ErrProjectNotFound
+		return nil, fmt.Errorf("failed move item: %w", err)
+	}
+	err = checkWritable(ctx, tx, fromProject, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("failed move item: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, queryCloseGap, fromProject, id, fromPriority)
+	if err != nil {
+		return nil, fmt.Errorf("failed close priority gap: %w", err)
+	}
+	closed, err := collectChanges(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed close priority gap: %w", err)
+	}
+
+	var maxPriority int
+	err = tx.QueryRow(ctx, queryMaxPriorityWithout, projectId, id).Scan(&maxPriority)
+	if err != nil {
+		return nil, fmt.Errorf("failed get max priority: %w", err)
+	}
+	if priority < 1 || priority > maxPriority+1 {
+		priority = maxPriority + 1
+	}
+	rows, err = tx.Query(ctx, queryShiftPriorities, projectId, id, priority)
+	if err != nil {
+		return nil, fmt.Errorf("failed shift priorities: %w", err)
+	}
+	opened, err := collectChanges(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed shift priorities: %w", err)
+	}
+
+	item, err := scanGoods(tx.QueryRow(ctx, queryMoveItem, projectId, priority, id))
+	if err == nil {
+		err = addRevision(ctx, tx, entity.Move, item, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed move item: %w", err)
+	}
+	shifted := mergeShifted(closed, opened)
+	res := &entity.MoveResult{
+		Item:          item,
+		FromProjectId: fromProject,
+		Shifted:       entity.ChangedGoods(shifted),
+		ShiftedPrev:   make([]entity.Goods, 0, len(shifted)),
+	}
+	for _, change := range shifted {
+		res.ShiftedPrev = append(res.ShiftedPrev, change.Prev)
+	}
+	err = addRevisions(ctx, tx, entity.Update, res.Shifted)
+	if err != nil {
+		return nil, fmt.Errorf("failed move item: %w", err)
+	}
+	return res, nil
+}
+
+// mergeShifted keeps the latest state of goods shifted twice when a good is
+// moved inside its own project, and the state before the first shift.
+func mergeShifted(closed, opened []entity.GoodsChange) []entity.GoodsChange {
+	pos := make(map[int]int, len(closed))
+	res := make([]entity.GoodsChange, 0, len(closed)+len(opened))
+	for _, change := range closed {
+		pos[change.Item.Id] = len(res)
+		res = append(res, change)
+	}
+	for _, change := range opened {
+		if i, ok := pos[change.Item.Id]; ok {
+			res[i].Item = change.Item
+			continue
+		}
+		res = append(res, change)
+	}
+	return res
 }
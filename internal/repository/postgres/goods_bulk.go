@@ -0,0 +1,292 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+const (
+	queryExistingProjects = `SELECT id FROM projects WHERE id = ANY($1)`
+	queryMaxPriorities    = `SELECT project_id, MAX(priority) FROM GOODS
+	WHERE project_id = ANY($1)
+	GROUP BY project_id`
+	queryNextGoodsIds = `SELECT nextval(pg_get_serial_sequence('goods', 'id'))
+	FROM generate_series(1, $1)`
+	queryGetItemsByIds = `SELECT ` + goodsColumns + `
+	FROM GOODS
+	WHERE id = ANY($1)
+	ORDER BY id`
+)
+
+var bulkColumns = []string{"id", "project_id", "name", "description", "status", "active_from", "active_to", "price",
+	"currency", "attributes", "priority"}
+
+// CreateItems copies all goods in one transaction. Ids are taken from the
+// sequence upfront so COPY can write them and the created rows can be read
+// back in request order, priorities continue MAX(priority) of each project.
+func (r *PgPool) CreateItems(ctx context.Context, items []entity.Goods) ([]entity.Goods, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.ReadCommitted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	res, err := createItemsTx(ctx, tx, items)
+	if err != nil {
+		return nil, fmt.Errorf("failed create items: %w", err)
+	}
+	return res, nil
+}
+
+func createItemsTx(ctx context.Context, tx pgx.Tx, items []entity.Goods) ([]entity.Goods, error) {
+	projects := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, item := range items {
+		if !seen[item.ProjectId] {
+			seen[item.ProjectId] = true
+			projects = append(projects, item.ProjectId)
+		}
+	}
+	// fixed lock order keeps concurrent bulk requests from deadlocking
+	sort.Ints(projects)
+
+	for _, projectId := range projects {
+		_, err := tx.Exec(ctx, queryLockProjectPriority, projectId)
+		if err != nil {
+			return nil, fmt.Errorf("failed while locking project priority: %w", err)
+		}
+	}
+
+	existing, err := queryIntSet(ctx, tx, queryExistingProjects, projects)
+	if err != nil {
+		return nil, fmt.Errorf("failed check projects: %w", err)
+	}
+	var bulkErr entity.BulkError
+	for i, item := range items {
+		if !existing[item.ProjectId] {
+			bulkErr.Rows = append(bulkErr.Rows, entity.RowError{
+				Index: i,
+				Error: fmt.Sprintf("project %d not found", item.ProjectId),
+			})
+		}
+	}
+	if len(bulkErr.Rows) > 0 {
+		return nil, &bulkErr
+	}
+	err = checkWritable(ctx, tx, projects...)
+	if err != nil {
+		return nil, err
+	}
+
+	priorities := make(map[int]int, len(projects))
+	rows, err := tx.Query(ctx, queryMaxPriorities, projects)
+	if err != nil {
+		return nil, fmt.Errorf("failed get max priorities: %w", err)
+	}
+	for rows.Next() {
+		var projectId, priority int
+		if err = rows.Scan(&projectId, &priority); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed get max priorities: %w", err)
+		}
+		priorities[projectId] = priority
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed get max priorities: %w", err)
+	}
+
+	ids, err := queryInts(ctx, tx, queryNextGoodsIds, len(items))
+	if err != nil {
+		return nil, fmt.Errorf("failed allocate ids: %w", err)
+	}
+	sort.Ints(ids)
+
+	now := time.Now()
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"goods"}, bulkColumns,
+		pgx.CopyFromSlice(len(items), func(i int) ([]any, error) {
+			priorities[items[i].ProjectId]++
+			return []any{
+				ids[i],
+				items[i].ProjectId,
+				items[i].Name,
+				items[i].Description,
+				items[i].InitialStatus(now),
+				utcTime(items[i].ActiveFrom),
+				utcTime(items[i].ActiveTo),
+				numericArg(items[i].Price),
+				items[i].Currency,
+				attributesOrEmpty(items[i].Attributes),
+				priorities[items[i].ProjectId],
+			}, nil
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed copy items: %w", err)
+	}
+
+	rows, err = tx.Query(ctx, queryGetItemsByIds, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed get created items: %w", err)
+	}
+	res, err := collectGoods(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed get created items: %w", err)
+	}
+	err = addRevisions(ctx, tx, entity.Create, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *PgPool) UpdateItems(ctx context.Context, sel entity.GoodsSelector, patch entity.GoodsPatch) ([]entity.GoodsChange, error) {
+	res, err := r.updateSelected(ctx, sel, entity.Update, func(b *sqlBuilder) []string {
+		return goodsPatchSets(b, patch)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed update items: %w", err)
+	}
+	return res, nil
+}
+
+func (r *PgPool) DeleteItems(ctx context.Context, sel entity.GoodsSelector) ([]entity.Goods, error) {
+	res, err := r.updateSelected(ctx, sel, entity.Delete, func(b *sqlBuilder) []string {
+		b.where("NOT removed")
+		return []string{"removed = true", "removed_at = NOW()"}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed delete items: %w", err)
+	}
+	return entity.ChangedGoods(res), nil
+}
+
+// selectorWhere builds the conditions of the selected goods.
+func selectorWhere(sel entity.GoodsSelector) *sqlBuilder {
+	if len(sel.Ids) > 0 {
+		b := newSQLBuilder()
+		b.where("id = ANY(" + b.arg(sel.Ids) + ")")
+		return b
+	}
+	return goodsFilterWhere(sel.Filter)
+}
+
+// lockSelected takes the priority locks of the projects holding selected
+// goods and repeats until no new project shows up.
+func lockSelected(ctx context.Context, tx pgx.Tx, sel entity.GoodsSelector) ([]int, error) {
+	locked := make(map[int]bool)
+	for {
+		b := selectorWhere(sel)
+		projects, err := queryInts(ctx, tx, `SELECT DISTINCT project_id FROM GOODS WHERE `+b.whereClause(), b.args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed get selected projects: %w", err)
+		}
+		fresh := 0
+		for _, projectId := range projects {
+			if !locked[projectId] {
+				fresh++
+			}
+		}
+		if fresh == 0 {
+			return projects, nil
+		}
+		err = lockProjects(ctx, tx, locked, projects)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// updateSelected applies the SET clauses to the selected goods in one
+// transaction and records a revision with action for each of them. When goods
+// are selected by ids, any id left untouched rolls back the whole update and
+// is reported in MissingIdsError. Goods that start to match in another
+// project once the locks are taken are left out. Each good is returned with
+// the row as it was, read by the prev CTE like in queryUpdateItem.
+func (r *PgPool) updateSelected(ctx context.Context, sel entity.GoodsSelector, action entity.EventAction, set func(b *sqlBuilder) []string) ([]entity.GoodsChange, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.ReadCommitted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	locked, err := lockSelected(ctx, tx, sel)
+	if err != nil {
+		return nil, err
+	}
+	b := selectorWhere(sel)
+	b.where("project_id = ANY(" + b.arg(locked) + ")")
+	sets := set(b)
+	sets = append(sets, bumpVersion)
+	query := fmt.Sprintf(`WITH prev AS (SELECT %s FROM GOODS WHERE %s FOR UPDATE),
+	upd AS (UPDATE GOODS SET %s WHERE id IN (SELECT id FROM prev) RETURNING %s)
+	SELECT upd.*, prev.* FROM upd JOIN prev ON prev.id = upd.id`,
+		goodsColumns, b.whereClause(), strings.Join(sets, ", "), goodsColumns)
+	rows, err := tx.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := collectChanges(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	affected := make(map[int]bool, len(res))
+	projects := make([]int, 0)
+	for _, change := range res {
+		affected[change.Item.Id] = true
+		projects = append(projects, change.Item.ProjectId)
+	}
+	err = checkWritable(ctx, tx, projects...)
+	if err != nil {
+		return nil, err
+	}
+	var missing []int
+	for _, id := range sel.Ids {
+		if !affected[id] {
+			affected[id] = true
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		err = &entity.MissingIdsError{Ids: missing}
+		return nil, err
+	}
+	err = addRevisions(ctx, tx, action, entity.ChangedGoods(res))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func queryInts(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]int, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+func queryIntSet(ctx context.Context, tx pgx.Tx, query string, args ...any) (map[int]bool, error) {
+	vals, err := queryInts(ctx, tx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int]bool, len(vals))
+	for _, val := range vals {
+		res[val] = true
+	}
+	return res, nil
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+// StreamItems feeds goods matching the filter to fn one row at a time as pgx
+// reads them from the connection, nothing is collected in memory.
+func (r *PgPool) StreamItems(ctx context.Context, filter entity.GoodsFilter, fn func(entity.Goods) error) error {
+	b := goodsFilterWhere(filter)
+	query := fmt.Sprintf(`SELECT %s FROM GOODS WHERE %s ORDER BY %s`,
+		goodsColumns, b.whereClause(), goodsOrderBy(filter.OrderBy()))
+	rows, err := r.db.Query(ctx, query, b.args...)
+	if err != nil {
+		return fmt.Errorf("failed stream goods: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		item, err := scanGoods(rows)
+		if err != nil {
+			return fmt.Errorf("failed parse into sturct: %w", err)
+		}
+		if err = fn(item); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed stream goods: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+var goodsSortColumns = map[entity.GoodsSortField]string{
+	entity.SortById:        "id",
+	entity.SortByProjectId: "project_id",
+	entity.SortByName:      "name",
+	entity.SortByPriority:  "priority",
+	entity.SortByCreatedAt: "created_at",
+}
+
+type sqlBuilder struct {
+	conds []string
+	args  []interface{}
+}
+
+func newSQLBuilder(args ...interface{}) *sqlBuilder {
+	return &sqlBuilder{args: args}
+}
+
+// arg registers a query argument and returns its placeholder.
+func (b *sqlBuilder) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *sqlBuilder) where(cond string) {
+	b.conds = append(b.conds, cond)
+}
+
+func (b *sqlBuilder) whereClause() string {
+	if len(b.conds) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(b.conds, " AND ")
+}
+
+func goodsFilterWhere(f entity.GoodsFilter) *sqlBuilder {
+	b := newSQLBuilder()
+	if f.ProjectId != nil {
+		b.where("project_id = " + b.arg(*f.ProjectId))
+	}
+	if f.Removed != nil {
+		b.where("removed = " + b.arg(*f.Removed))
+	}
+	if f.CreatedAfter != nil {
+		b.where("created_at > " + b.arg(*f.CreatedAfter))
+	}
+	if f.CreatedBefore != nil {
+		b.where("created_at < " + b.arg(*f.CreatedBefore))
+	}
+	if f.PriorityGt != nil {
+		b.where("priority > " + b.arg(*f.PriorityGt))
+	}
+	if f.PriorityLt != nil {
+		b.where("priority < " + b.arg(*f.PriorityLt))
+	}
+	if f.PriceMin != nil {
+		b.where("price >= " + b.arg(numericArg(f.PriceMin)))
+	}
+	if f.PriceMax != nil {
+		b.where("price <= " + b.arg(numericArg(f.PriceMax)))
+	}
+	if f.Currency != "" {
+		b.where("currency = " + b.arg(f.Currency))
+	}
+	whereTag(b, f.Tag)
+	whereAttributes(b, f.Attributes)
+	return b
+}
+
+// whereAttributes matches each attribute by containment so the GIN index is
+// used. Query values are strings, one that reads as a JSON number or boolean
+// also matches the typed value, ?attr.size=42 finds both "42" and 42.
+func whereAttributes(b *sqlBuilder, attrs map[string]string) {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		val := attrs[key]
+		cond := "attributes @> " + b.arg(map[string]interface{}{key: val})
+		var typed interface{}
+		if json.Unmarshal([]byte(val), &typed) == nil {
+			switch typed.(type) {
+			case float64, bool:
+				cond = "(" + cond + " OR attributes @> " + b.arg(map[string]interface{}{key: typed}) + ")"
+			}
+		}
+		b.where(cond)
+	}
+}
+
+// whereTag keeps goods carrying the tag, an empty tag matches everything.
+func whereTag(b *sqlBuilder, tag string) {
+	if tag == "" {
+		return
+	}
+	b.where(`id IN (SELECT gt.goods_id FROM goods_tags gt JOIN tags t ON t.id = gt.tag_id
+	WHERE t.name = ` + b.arg(tag) + `)`)
+}
+
+func goodsOrderBy(sort []entity.GoodsSort) string {
+	cols := make([]string, 0, len(sort))
+	for _, s := range sort {
+		col := goodsSortColumns[s.Field]
+		if s.Desc {
+			col += " DESC"
+		}
+		cols = append(cols, col)
+	}
+	return strings.Join(cols, ", ")
+}
+
+// goodsCursorValue returns nil when the cursor lacks the field, ids,
+// priorities and names are never zero in stored goods.
+func goodsCursorValue(c *entity.Cursor, field entity.GoodsSortField) interface{} {
+	switch field {
+	case entity.SortByProjectId:
+		if c.ProjectId == 0 {
+			return nil
+		}
+		return c.ProjectId
+	case entity.SortByName:
+		if c.Name == "" {
+			return nil
+		}
+		return c.Name
+	case entity.SortByPriority:
+		if c.Priority == 0 {
+			return nil
+		}
+		return c.Priority
+	case entity.SortByCreatedAt:
+		if c.CreatedAt == nil {
+			return nil
+		}
+		return *c.CreatedAt
+	default:
+		return c.Id
+	}
+}
+
+// goodsKeyset adds the condition selecting rows strictly after the cursor in
+// the given order: (a > x) OR (a = x AND b > y) OR ..., with the comparison
+// flipped for descending columns. A cursor issued for another order is
+// rejected.
+func goodsKeyset(b *sqlBuilder, sort []entity.GoodsSort, after *entity.Cursor) error {
+	if after == nil {
+		return nil
+	}
+	if after.Sort != entity.SortKey(sort) {
+		return entity.ErrInvalidCursor
+	}
+	branches := make([]string, 0, len(sort))
+	for i, s := range sort {
+		parts := make([]string, 0, i+1)
+		for _, prev := range sort[:i] {
+			value := goodsCursorValue(after, prev.Field)
+			if value == nil {
+				return entity.ErrInvalidCursor
+			}
+			parts = append(parts, goodsSortColumns[prev.Field]+" = "+b.arg(value))
+		}
+		value := goodsCursorValue(after, s.Field)
+		if value == nil {
+			return entity.ErrInvalidCursor
+		}
+		op := " > "
+		if s.Desc {
+			op = " < "
+		}
+		parts = append(parts, goodsSortColumns[s.Field]+op+b.arg(value))
+		branches = append(branches, "("+strings.Join(parts, " AND ")+")")
+	}
+	b.where("(" + strings.Join(branches, " OR ") + ")")
+	return nil
+}
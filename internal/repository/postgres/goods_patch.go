@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+// goodsPatchSets builds SET clauses for the non-nil fields of the patch.
+func goodsPatchSets(b *sqlBuilder, patch entity.GoodsPatch) []string {
+	var sets []string
+	if patch.Name != nil {
+		sets = append(sets, "name = "+b.arg(*patch.Name))
+	}
+	if patch.ClearDescription {
+		sets = append(sets, "description = NULL")
+	} else if patch.Description != nil {
+		sets = append(sets, "description = "+b.arg(*patch.Description))
+	}
+	if patch.Removed != nil {
+		removed := b.arg(*patch.Removed)
+		sets = append(sets,
+			"removed = "+removed,
+			"removed_at = CASE WHEN "+removed+" THEN COALESCE(removed_at, NOW()) END")
+	}
+	if patch.ClearPrice {
+		sets = append(sets, "price = NULL", "currency = NULL")
+	} else if patch.Price != nil {
+		sets = append(sets, "price = "+b.arg(numericArg(patch.Price)), "currency = "+b.arg(*patch.Currency))
+	}
+	if len(patch.Attributes) > 0 {
+		set := make(map[string]interface{}, len(patch.Attributes))
+		del := []string{}
+		for key, val := range patch.Attributes {
+			if val == nil {
+				del = append(del, key)
+			} else {
+				set[key] = val
+			}
+		}
+		sets = append(sets, "attributes = (attributes || "+b.arg(set)+"::jsonb) - "+b.arg(del)+"::text[]")
+	}
+	return sets
+}
+
+// attributesOrEmpty stores missing attributes as an empty object.
+func attributesOrEmpty(attrs map[string]interface{}) map[string]interface{} {
+	if attrs == nil {
+		return map[string]interface{}{}
+	}
+	return attrs
+}
+
+// nullableAttributes passes missing attributes as SQL NULL, a nil map would
+// be encoded as the JSON null.
+func nullableAttributes(attrs map[string]interface{}) any {
+	if attrs == nil {
+		return nil
+	}
+	return attrs
+}
+
+// PatchItem writes only the patch fields that differ from the stored row and
+// returns them. The version check and conflict handling follow UpdateItem, a
+// patch without changes leaves the row and its version as they are. The good
+// is returned as it was before the patch.
+func (r *PgPool) PatchItem(ctx context.Context, item *entity.Goods, patch entity.GoodsPatch) (entity.Goods, entity.GoodsPatch, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.ReadCommitted,
+	})
+	if err != nil {
+		return entity.Goods{}, entity.GoodsPatch{}, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	_, err = lockItemProject(ctx, tx, item.Id)
+	if err != nil {
+		return entity.Goods{}, entity.GoodsPatch{}, fmt.Errorf("failed patch item: %w", err)
+	}
+
+	current, err := currentItem(ctx, tx, item)
+	if err != nil {
+		return entity.Goods{}, entity.GoodsPatch{}, fmt.Errorf("failed patch item: %w", err)
+	}
+	changes := patch.Changes(current)
+	if changes.Empty() {
+		*item = current
+		return current, changes, nil
+	}
+	err = patchItemTx(ctx, tx, item, changes, nil)
+	if err != nil {
+		return entity.Goods{}, entity.GoodsPatch{}, fmt.Errorf("failed patch item: %w", err)
+	}
+	return current, changes, nil
+}
+
+// currentItem reads and locks the good to be patched and checks that it may be
+// written at item.Version, on a mismatch item is overwritten with the current
+// row.
+func currentItem(ctx context.Context, tx pgx.Tx, item *entity.Goods) (entity.Goods, error) {
+	current, err := scanGoods(tx.QueryRow(ctx, queryGetItemLocked, item.Id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrNotFound
+		}
+		return current, err
+	}
+	err = checkWritable(ctx, tx, current.ProjectId)
+	if err != nil {
+		return current, err
+	}
+	if item.Version != 0 && item.Version != current.Version {
+		*item = current
+		return current, entity.ErrVersionConflict
+	}
+	return current, nil
+}
+
+// patchItemTx writes non-empty changes and records the revision.
+func patchItemTx(ctx context.Context, tx pgx.Tx, item *entity.Goods, changes entity.GoodsPatch, revertedFrom *int) error {
+	b := newSQLBuilder()
+	sets := append(goodsPatchSets(b, changes), bumpVersion)
+	b.where("id = " + b.arg(item.Id))
+	query := fmt.Sprintf(`UPDATE GOODS SET %s WHERE %s RETURNING %s`,
+		strings.Join(sets, ", "), b.whereClause(), goodsColumns)
+	updated, err := scanGoods(tx.QueryRow(ctx, query, b.args...))
+	if err != nil {
+		return err
+	}
+	err = addRevision(ctx, tx, entity.Update, updated, revertedFrom)
+	if err != nil {
+		return err
+	}
+	*item = updated
+	return nil
+}
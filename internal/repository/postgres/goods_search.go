@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+const (
+	searchQuery = `WITH q AS (
+		SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) AS query
+	)`
+	searchWhere = `(search_vector @@ q.query OR $1 <% name OR $1 <% description) AND ($2 OR NOT removed)`
+
+	queryCountSearchItems = searchQuery + `
+	SELECT COUNT(*) FROM GOODS, q
+	WHERE ` + searchWhere
+	querySearchItems = searchQuery + `
+	SELECT ` + goodsColumns + `,
+		ts_rank_cd(search_vector, q.query) + word_similarity($1, name) AS rank,
+		ts_headline('russian', name || ' ' || COALESCE(description, ''), q.query,
+			'StartSel=<b>, StopSel=</b>, MaxWords=20, MinWords=5, HighlightAll=false') AS snippet
+	FROM GOODS, q
+	WHERE ` + searchWhere + `
+	ORDER BY rank DESC, id
+	LIMIT $3 OFFSET $4`
+)
+
+// SearchItems ranks goods by full-text match of name and description in both
+// russian and english configs, trigram word similarity keeps typos matching.
+// Ranked results are paged by offset carried in the cursor.
+func (r *PgPool) SearchItems(ctx context.Context, query string, includeRemoved bool, page entity.Page) (*entity.GoodsSearchResponse, error) {
+	tx, err := r.beginSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	var total int
+	err = tx.QueryRow(ctx, queryCountSearchItems, query, includeRemoved).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed count search goods: %w", err)
+	}
+
+	var offset int
+	if page.After != nil {
+		offset = page.After.Offset
+	}
+	rows, err := tx.Query(ctx, querySearchItems, query, includeRemoved, page.Limit+1, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed search goods: %w", err)
+	}
+	defer rows.Close()
+
+	res := &entity.GoodsSearchResponse{
+		Hits: make([]entity.GoodsSearchHit, 0, page.Limit),
+		Meta: entity.PageMeta{Limit: page.Limit, Total: total},
+	}
+	for rows.Next() {
+		var hit entity.GoodsSearchHit
+		err = rows.Scan(append(goodsFields(&hit.Goods), &hit.Rank, &hit.Snippet)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed parse into sturct: %w", err)
+		}
+		res.Hits = append(res.Hits, hit)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed search goods: %w", err)
+	}
+	if len(res.Hits) > page.Limit {
+		res.Hits = res.Hits[:page.Limit]
+		res.Meta.NextCursor = entity.Cursor{
+			Id:     res.Hits[page.Limit-1].Id,
+			Offset: offset + page.Limit,
+		}.Encode()
+	}
+	return res, nil
+}
@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+const (
+	querySetItemStatus = `UPDATE GOODS SET status = $1, ` + bumpVersion + `
+	WHERE id = $2 AND status = $3
+	RETURNING ` + goodsColumns
+	querySetItemWindow = `UPDATE GOODS SET active_from = $1, active_to = $2, status = $3, ` + bumpVersion + `
+	WHERE id = $4
+	RETURNING ` + goodsColumns
+	// windowChange updates the goods selected by its subquery, which has to
+	// return old_id and old_status, and returns the previous status first.
+	windowChange = `UPDATE GOODS SET status = $1, ` + bumpVersion + `
+	FROM (%s) old
+	WHERE id = old.old_id
+	RETURNING old.old_status, ` + goodsColumns
+	whereWindowWritable = `NOT removed AND project_id NOT IN (SELECT id FROM projects WHERE status = 'archived')`
+	queryActivateDue    = `SELECT id AS old_id, status AS old_status FROM GOODS
+	WHERE status = 'draft' AND active_from <= $2 AND (active_to IS NULL OR active_to > $2) AND ` + whereWindowWritable + `
+	FOR UPDATE`
+	queryExpireDue = `SELECT id AS old_id, status AS old_status FROM GOODS
+	WHERE status <> 'expired' AND active_to <= $2 AND ` + whereWindowWritable + `
+	FOR UPDATE`
+)
+
+// SetItemStatus follows SetProjectStatus, the status changes only while the
+// good is still in from.
+func (r *PgPool) SetItemStatus(ctx context.Context, id int, from, to entity.GoodsStatus) (*entity.Goods, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.ReadCommitted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	_, err = lockItemProject(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed set item status: %w", err)
+	}
+	item, err := scanGoods(tx.QueryRow(ctx, querySetItemStatus, to, id, from))
+	if errors.Is(err, pgx.ErrNoRows) {
+		_, err = scanGoods(tx.QueryRow(ctx, queryGetItem, id))
+		if err == nil {
+			err = entity.ErrVersionConflict
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrNotFound
+		}
+	}
+	if err == nil {
+		err = checkWritable(ctx, tx, item.ProjectId)
+	}
+	if err == nil {
+		err = addRevision(ctx, tx, entity.Status, item, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed set item status: %w", err)
+	}
+	return &item, nil
+}
+
+// SetItemWindow moves the status along with the window in the same
+// transaction, see Goods.WindowStatus. The good is returned with the row as it
+// was.
+func (r *PgPool) SetItemWindow(ctx context.Context, id int, from, to *time.Time, now time.Time) (*entity.GoodsChange, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.ReadCommitted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	_, err = lockItemProject(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed set item window: %w", err)
+	}
+	current, err := currentItem(ctx, tx, &entity.Goods{Id: id})
+	if err != nil {
+		return nil, fmt.Errorf("failed set item window: %w", err)
+	}
+	change := entity.GoodsChange{Prev: current}
+	current.ActiveFrom, current.ActiveTo = utcTime(from), utcTime(to)
+	change.Item, err = scanGoods(tx.QueryRow(ctx, querySetItemWindow,
+		current.ActiveFrom, current.ActiveTo, current.WindowStatus(now), id))
+	if err == nil {
+		err = addRevision(ctx, tx, entity.Update, change.Item, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed set item window: %w", err)
+	}
+	return &change, nil
+}
+
+// ActivateDueItems makes draft goods active once their window has opened.
+// Removed goods and goods of archived projects are left alone.
+func (r *PgPool) ActivateDueItems(ctx context.Context, now time.Time) ([]entity.WindowChange, error) {
+	res, err := r.changeWindowStatus(ctx, queryActivateDue, entity.GoodsActive, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed activate goods: %w", err)
+	}
+	return res, nil
+}
+
+// ExpireDueItems expires goods of any other status once their window has
+// closed.
+func (r *PgPool) ExpireDueItems(ctx context.Context, now time.Time) ([]entity.WindowChange, error) {
+	res, err := r.changeWindowStatus(ctx, queryExpireDue, entity.GoodsExpired, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed expire goods: %w", err)
+	}
+	return res, nil
+}
+
+// changeWindowStatus runs as a single statement, the row locks of the
+// subquery are enough and the goods table stays unlocked. The revisions are
+// written in the same transaction.
+func (r *PgPool) changeWindowStatus(ctx context.Context, due string, to entity.GoodsStatus, now time.Time) ([]entity.WindowChange, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.ReadCommitted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(windowChange, due), to, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	var res []entity.WindowChange
+	goods := make([]entity.Goods, 0)
+	for rows.Next() {
+		var change entity.WindowChange
+		err = rows.Scan(append([]any{&change.From}, goodsFields(&change.Item)...)...)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed parse into sturct: %w", err)
+		}
+		res = append(res, change)
+		goods = append(goods, change.Item)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	err = addRevisions(ctx, tx, entity.Status, goods)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// utcTime converts window bounds before they are written, timestamp columns
+// keep the wall clock and drop the offset.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
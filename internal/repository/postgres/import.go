@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+const (
+	importJobColumns = `id, project_id, status, upsert, total, processed, created, updated, error, created_at, finished_at`
+
+	queryCreateImportJob = `INSERT INTO import_jobs (project_id, status, upsert, total)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, created_at`
+	queryUpdateImportJob = `UPDATE import_jobs SET status = $1, processed = $2, created = $3, updated = $4, error = $5,
+	finished_at = CASE WHEN $1 IN ('done', 'failed') THEN NOW() END
+	WHERE id = $6
+	RETURNING finished_at`
+	queryGetImportJob          = `SELECT ` + importJobColumns + ` FROM import_jobs WHERE id = $1`
+	queryFailUnfinishedImports = `UPDATE import_jobs SET status = 'failed', error = $1, finished_at = NOW()
+	WHERE status IN ('pending', 'running')`
+	// queryUpsertByName returns the rows like queryUpdateItem
+	queryUpsertByName = `WITH prev AS (SELECT ` + goodsColumns + ` FROM GOODS
+	WHERE project_id = $1 AND name = ANY($2) AND NOT removed FOR UPDATE),
+	upd AS (UPDATE GOODS SET description = v.new_description, ` + bumpVersion + `
+	FROM unnest($2::text[], $3::text[]) AS v(new_name, new_description)
+	WHERE id IN (SELECT id FROM prev) AND name = v.new_name
+	RETURNING ` + goodsColumns + `)
+	SELECT upd.*, prev.* FROM upd JOIN prev ON prev.id = upd.id`
+)
+
+func (r *PgPool) CreateImportJob(ctx context.Context, job *entity.ImportJob) error {
+	err := r.db.QueryRow(ctx, queryCreateImportJob,
+		job.ProjectId,
+		job.Status,
+		job.Upsert,
+		job.Total,
+	).Scan(&job.Id, &job.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed create import job: %w", err)
+	}
+	return nil
+}
+
+func (r *PgPool) UpdateImportJob(ctx context.Context, job *entity.ImportJob) error {
+	err := r.db.QueryRow(ctx, queryUpdateImportJob,
+		job.Status,
+		job.Processed,
+		job.Created,
+		job.Updated,
+		job.Error,
+		job.Id,
+	).Scan(&job.FinishedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrNotFound
+		}
+		return fmt.Errorf("failed update import job: %w", err)
+	}
+	return nil
+}
+
+func (r *PgPool) GetImportJob(ctx context.Context, id int) (*entity.ImportJob, error) {
+	var job entity.ImportJob
+	err := r.db.QueryRow(ctx, queryGetImportJob, id).Scan(
+		&job.Id,
+		&job.ProjectId,
+		&job.Status,
+		&job.Upsert,
+		&job.Total,
+		&job.Processed,
+		&job.Created,
+		&job.Updated,
+		&job.Error,
+		&job.CreatedAt,
+		&job.FinishedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed get import job: %w", err)
+	}
+	return &job, nil
+}
+
+// FailUnfinishedImportJobs fails the jobs a previous run left pending or
+// running, it is called before new imports are accepted.
+func (r *PgPool) FailUnfinishedImportJobs(ctx context.Context, reason string) (int, error) {
+	tag, err := r.db.Exec(ctx, queryFailUnfinishedImports, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed fail unfinished import jobs: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// UpsertItems updates the description of live goods of the project that
+// share a name with an imported row and creates the rest. Names are expected
+// to be unique within items.
+func (r *PgPool) UpsertItems(ctx context.Context, projectId int, items []entity.Goods) ([]entity.Goods, []entity.GoodsChange, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.ReadCommitted,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	_, err = tx.Exec(ctx, queryLockProjectPriority, projectId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed while locking project priority: %w", err)
+	}
+	err = checkWritable(ctx, tx, projectId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed upsert items: %w", err)
+	}
+
+	names := make([]string, 0, len(items))
+	descriptions := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.Name)
+		descriptions = append(descriptions, item.Description)
+	}
+	rows, err := tx.Query(ctx, queryUpsertByName, projectId, names, descriptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed upsert items: %w", err)
+	}
+	updated, err := collectChanges(rows)
+	if err == nil {
+		err = addRevisions(ctx, tx, entity.Update, entity.ChangedGoods(updated))
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed upsert items: %w", err)
+	}
+
+	found := make(map[string]bool, len(updated))
+	for _, change := range updated {
+		found[change.Item.Name] = true
+	}
+	rest := make([]entity.Goods, 0, len(items))
+	for _, item := range items {
+		if !found[item.Name] {
+			item.ProjectId = projectId
+			rest = append(rest, item)
+		}
+	}
+	if len(rest) == 0 {
+		return nil, updated, nil
+	}
+	created, err := createItemsTx(ctx, tx, rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed upsert items: %w", err)
+	}
+	return created, updated, nil
+}
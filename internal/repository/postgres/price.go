@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+// queryProjectPrices groups the live goods by currency, goods without a price
+// fall into the NULL group.
+const queryProjectPrices = `SELECT currency, COUNT(*), MIN(price), MAX(price), ROUND(AVG(price), 4)
+	FROM GOODS
+	WHERE project_id = $1 AND NOT removed
+	GROUP BY currency
+	ORDER BY currency`
+
+// numericScanner reads NUMERIC into a decimal without passing through float
+// or text, NULL leaves a nil pointer.
+type numericScanner struct {
+	dst **decimal.Decimal
+}
+
+func (s numericScanner) ScanNumeric(v pgtype.Numeric) error {
+	if !v.Valid {
+		*s.dst = nil
+		return nil
+	}
+	if v.NaN || v.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("numeric %v is not a finite number", v)
+	}
+	d := decimal.NewFromBigInt(v.Int, v.Exp)
+	*s.dst = &d
+	return nil
+}
+
+// numericArg encodes an optional decimal as NUMERIC, the binary COPY
+// protocol does not accept the text form decimal.Decimal offers.
+func numericArg(d *decimal.Decimal) pgtype.Numeric {
+	if d == nil {
+		return pgtype.Numeric{}
+	}
+	return pgtype.Numeric{Int: d.Coefficient(), Exp: d.Exponent(), Valid: true}
+}
+
+func (r *PgPool) GetProjectSummary(ctx context.Context, id int) (*entity.ProjectSummary, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, queryProjectExists, id).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed get project summary: %w", err)
+	}
+	if !exists {
+		return nil, entity.ErrNotFound
+	}
+
+	rows, err := r.db.Query(ctx, queryProjectPrices, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed get project summary: %w", err)
+	}
+	defer rows.Close()
+	res := &entity.ProjectSummary{ProjectId: id, Prices: make([]entity.PriceStats, 0)}
+	for rows.Next() {
+		var currency *string
+		var count int
+		var min, max, avg *decimal.Decimal
+		err = rows.Scan(&currency, &count, numericScanner{&min}, numericScanner{&max}, numericScanner{&avg})
+		if err != nil {
+			return nil, fmt.Errorf("failed get project summary: %w", err)
+		}
+		res.Goods += count
+		if currency == nil {
+			continue
+		}
+		res.Priced += count
+		res.Prices = append(res.Prices, entity.PriceStats{
+			Currency: *currency,
+			Count:    count,
+			Min:      *min,
+			Max:      *max,
+			Avg:      *avg,
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed get project summary: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+const (
+	querySetProjectStatus = `UPDATE projects SET status = $1, version = version + 1, updated_at = NOW()
+	WHERE id = $2 AND status = $3
+	RETURNING ` + projectColumns
+	queryLockProjectStatus = `SELECT status FROM projects WHERE id = ANY($1) FOR SHARE`
+)
+
+// SetProjectStatus changes the status only while the project is still in
+// from, otherwise the project is returned as it is with ErrVersionConflict.
+func (r *PgPool) SetProjectStatus(ctx context.Context, id int, from, to entity.ProjectStatus) (*entity.Project, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.Serializable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	_, err = tx.Exec(ctx, queryLockProjects)
+	if err != nil {
+		return nil, fmt.Errorf("failed while locking projects: %w", err)
+	}
+	project, err := scanProject(tx.QueryRow(ctx, querySetProjectStatus, to, id, from))
+	if errors.Is(err, pgx.ErrNoRows) {
+		project, err = scanProject(tx.QueryRow(ctx, queryGetProject, id))
+		if err == nil {
+			err = entity.ErrVersionConflict
+			return &project, fmt.Errorf("failed set project status: %w", err)
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrNotFound
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed set project status: %w", err)
+	}
+	return &project, nil
+}
+
+// checkWritable fails with ErrProjectArchived when one of the projects is
+// archived. The project rows stay share locked until the transaction ends,
+// so none of them can be archived while goods are written.
+func checkWritable(ctx context.Context, tx pgx.Tx, projects ...int) error {
+	rows, err := tx.Query(ctx, queryLockProjectStatus, projects)
+	if err != nil {
+		return fmt.Errorf("failed check project status: %w", err)
+	}
+	statuses, err := pgx.CollectRows(rows, pgx.RowTo[entity.ProjectStatus])
+	if err != nil {
+		return fmt.Errorf("failed check project status: %w", err)
+	}
+	for _, status := range statuses {
+		if status == entity.ProjectArchived {
+			return entity.ErrProjectArchived
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
@@ -9,44 +10,96 @@ import (
 )
 
 const (
-	queryGetProjects   = `SELECT id, name, created_at FROM projects`
-	queryGetProject    = `SELECT id, name, created_at FROM projects WHERE id = $1`
-	queryUpdateProject = `UPDATE projects SET name = $1 WHERE id = $2`
+	projectColumns = `id, name, status, version, created_at, updated_at`
+
+	whereProjectStatus = `($1 = '' OR status = $1)`
+	queryCountProjects = `SELECT COUNT(*) FROM projects WHERE ` + whereProjectStatus
+	queryGetProjects   = `SELECT ` + projectColumns + ` FROM projects
+	WHERE ` + whereProjectStatus + ` AND id > $2
+	ORDER BY id
+	LIMIT $3`
+	queryGetProject    = `SELECT ` + projectColumns + ` FROM projects WHERE id = $1`
+	queryUpdateProject = `UPDATE projects SET name = $1, version = version + 1, updated_at = NOW()
+	WHERE id = $2 AND ($3 = 0 OR version = $3)
+	RETURNING ` + projectColumns
 	queryLockProjects  = `LOCK TABLE projects IN ACCESS EXCLUSIVE MODE`
-	queryAddProject    = `INSERT INTO projects(name) VALUES($1)`
-	queryDeleteProject = `DELETE FROM projects WHERE id = $1`
+	queryAddProject    = `INSERT INTO projects(name) VALUES($1) RETURNING ` + projectColumns
+	queryDeleteProject = `DELETE FROM projects WHERE id = $1 RETURNING ` + projectColumns
+	queryHasLiveGoods  = `SELECT EXISTS(SELECT 1 FROM GOODS WHERE project_id = $1 AND NOT removed)`
+	queryDeleteGoods   = `DELETE FROM GOODS WHERE project_id = $1 RETURNING ` + goodsColumns
+	queryCloneGoods    = `INSERT INTO GOODS (project_id, name, description, status, active_from, active_to, price, currency,
+		attributes, priority)
+	SELECT $2, name, description, status, active_from, active_to, price, currency, attributes, ROW_NUMBER() OVER (ORDER BY priority, id)
+	FROM GOODS WHERE project_id = $1 AND NOT removed
+	RETURNING id`
+	// queryCloneGoodsTags pairs originals and copies by their rank, which is
+	// the priority of the copy
+	queryCloneGoodsTags = `INSERT INTO goods_tags (goods_id, tag_id)
+	SELECT n.id, gt.tag_id
+	FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY priority, id) AS rank
+		FROM GOODS WHERE project_id = $1 AND NOT removed) o
+	JOIN goods_tags gt ON gt.goods_id = o.id
+	JOIN GOODS n ON n.project_id = $2 AND n.priority = o.rank`
 )
 
-func (r *PgPool) GetProjects(ctx context.Context) ([]entity.Project, error) {
-	rows, err := r.db.Query(ctx, queryGetProjects)
+func scanProject(row pgx.Row) (entity.Project, error) {
+	var val entity.Project
+	err := row.Scan(
+		&val.Id,
+		&val.Name,
+		&val.Status,
+		&val.Version,
+		&val.CreatedAt,
+		&val.UpdatedAt,
+	)
+	return val, err
+}
+
+func (r *PgPool) GetProjects(ctx context.Context, filter entity.ProjectFilter, page entity.Page) (*entity.ProjectResponse, error) {
+	tx, err := r.beginSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	var total int
+	err = tx.QueryRow(ctx, queryCountProjects, filter.Status).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("error count projects: %w", err)
+	}
+	var afterId int
+	if page.After != nil {
+		afterId = page.After.Id
+	}
+	rows, err := tx.Query(ctx, queryGetProjects, filter.Status, afterId, page.Limit+1)
 	if err != nil {
 		return nil, fmt.Errorf("error get all projects: %w", err)
 	}
 	defer rows.Close()
-	var res []entity.Project
+	res := &entity.ProjectResponse{
+		Project: make([]entity.Project, 0, page.Limit),
+		Meta:    entity.PageMeta{Limit: page.Limit, Total: total},
+	}
 	for rows.Next() {
-		var val entity.Project
-		err = rows.Scan(
-			&val.Id,
-			&val.Name,
-			&val.CreatedAt,
-		)
+		val, err := scanProject(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scan into projects struct")
 		}
-		res = append(res, val)
+		res.Project = append(res.Project, val)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error get all projects: %w", err)
+	}
+	if len(res.Project) > page.Limit {
+		res.Project = res.Project[:page.Limit]
+		res.Meta.NextCursor = entity.Cursor{Id: res.Project[page.Limit-1].Id}.Encode()
 	}
 	return res, nil
 }
 
 func (r *PgPool) GetProject(ctx context.Context, id int) (*entity.Project, error) {
-	var val entity.Project
-	err := r.db.QueryRow(ctx, queryGetProject, id).Scan(
-		&val.Id,
-		&val.Name,
-		&val.CreatedAt,
-	)
-
+	val, err := scanProject(r.db.QueryRow(ctx, queryGetProject, id))
 	if err != nil {
 		return nil, entity.ErrNotFound
 	}
@@ -54,6 +107,7 @@ func (r *PgPool) GetProject(ctx context.Context, id int) (*entity.Project, error
 	return &val, nil
 }
 
+// UpdateProject follows the UpdateItem version check.
 func (r *PgPool) UpdateProject(ctx context.Context, item *entity.Project) error {
 	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel: pgx.Serializable,
@@ -68,13 +122,24 @@ func (r *PgPool) UpdateProject(ctx context.Context, item *entity.Project) error
 	if err != nil {
 		return fmt.Errorf("failed while locking projects: %w", err)
 	}
-	_, err = tx.Exec(ctx, queryUpdateProject,
+	updated, err := scanProject(tx.QueryRow(ctx, queryUpdateProject,
 		item.Name,
 		item.Id,
-	)
+		item.Version,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		updated, err = scanProject(tx.QueryRow(ctx, queryGetProject, item.Id))
+		if err == nil {
+			*item = updated
+			err = entity.ErrVersionConflict
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrNotFound
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("failed update project: %w", err)
 	}
+	*item = updated
 	return nil
 }
 
@@ -92,30 +157,111 @@ func (r *PgPool) AddProject(ctx context.Context, item *entity.Project) error {
 	if err != nil {
 		return fmt.Errorf("failed while locking projects: %w", err)
 	}
-	_, err = tx.Exec(ctx, queryAddProject, item.Name)
+	created, err := scanProject(tx.QueryRow(ctx, queryAddProject, item.Name))
 	if err != nil {
 		return fmt.Errorf("failed create project: %w", err)
 	}
+	*item = created
 	return nil
 }
 
-func (r *PgPool) DeleteProject(ctx context.Context, id int) error {
+// DeleteProject deletes the goods of the project explicitly before the
+// project itself, so the rows the cascade would drop silently are returned.
+// Without force a project with live goods is kept and ErrProjectNotEmpty
+// returned.
+func (r *PgPool) DeleteProject(ctx context.Context, id int, force bool) (*entity.Project, []entity.Goods, error) {
 	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel: pgx.Serializable,
 	})
 	if err != nil {
-		return fmt.Errorf("failed begin tx: %w", err)
+		return nil, nil, fmt.Errorf("failed begin tx: %w", err)
 	}
 
 	defer execTx(ctx, tx, &err)
 
 	_, err = tx.Exec(ctx, queryLockProjects)
 	if err != nil {
-		return fmt.Errorf("failed while locking projects: %w", err)
+		return nil, nil, fmt.Errorf("failed while locking projects: %w", err)
 	}
-	_, err = tx.Exec(ctx, queryDeleteProject, id)
+	_, err = scanProject(tx.QueryRow(ctx, queryGetProject, id))
 	if err != nil {
-		return fmt.Errorf("failed delete project: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed delete project: %w", err)
 	}
-	return nil
+	if !force {
+		var notEmpty bool
+		err = tx.QueryRow(ctx, queryHasLiveGoods, id).Scan(&notEmpty)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed check project goods: %w", err)
+		}
+		if notEmpty {
+			err = entity.ErrProjectNotEmpty
+			return nil, nil, fmt.Errorf("failed delete project: %w", err)
+		}
+	}
+	rows, err := tx.Query(ctx, queryDeleteGoods, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed delete project goods: %w", err)
+	}
+	goods, err := collectGoods(rows)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed delete project goods: %w", err)
+	}
+	project, err := scanProject(tx.QueryRow(ctx, queryDeleteProject, id))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed delete project: %w", err)
+	}
+	return &project, goods, nil
+}
+
+// CloneProject copies the project under a new name together with its live
+// goods. Priorities of the copies are renumbered from 1 in the original order.
+func (r *PgPool) CloneProject(ctx context.Context, id int, name string) (*entity.Project, []entity.Goods, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.Serializable,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	_, err = tx.Exec(ctx, queryLockProjects)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed while locking projects: %w", err)
+	}
+	_, err = scanProject(tx.QueryRow(ctx, queryGetProject, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed clone project: %w", err)
+	}
+	project, err := scanProject(tx.QueryRow(ctx, queryAddProject, name))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed create project: %w", err)
+	}
+	ids, err := queryInts(ctx, tx, queryCloneGoods, id, project.Id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed clone goods: %w", err)
+	}
+	_, err = tx.Exec(ctx, queryCloneGoodsTags, id, project.Id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed clone goods tags: %w", err)
+	}
+	rows, err := tx.Query(ctx, queryGetItemsByIds, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed get cloned goods: %w", err)
+	}
+	goods, err := collectGoods(rows)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed get cloned goods: %w", err)
+	}
+	err = addRevisions(ctx, tx, entity.Create, goods)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed clone goods: %w", err)
+	}
+	return &project, goods, nil
 }
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+const (
+	queryAddRevision = `INSERT INTO goods_revisions (goods_id, revision, action, reverted_from, data)
+	VALUES ($1, $2, $3, $4, $5)`
+	queryGetRevisions = `SELECT revision, action, reverted_from, data, created_at
+	FROM goods_revisions
+	WHERE goods_id = $1
+	ORDER BY revision`
+	queryGetRevision = `SELECT revision, action, reverted_from, data, created_at
+	FROM goods_revisions
+	WHERE goods_id = $1 AND revision = $2`
+	queryItemExists = `SELECT EXISTS(SELECT 1 FROM GOODS WHERE id = $1)`
+)
+
+var revisionColumns = []string{"goods_id", "revision", "action", "data"}
+
+// addRevision snapshots item in the transaction that wrote it, so the history
+// never disagrees with the row.
+func addRevision(ctx context.Context, tx pgx.Tx, action entity.EventAction, item entity.Goods, revertedFrom *int) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed marshal revision: %w", err)
+	}
+	_, err = tx.Exec(ctx, queryAddRevision, item.Id, item.Version, action, revertedFrom, data)
+	if err != nil {
+		return fmt.Errorf("failed add revision: %w", err)
+	}
+	return nil
+}
+
+// addRevisions snapshots the goods written by one statement, the rows are
+// copied so bulk writes and imports do not pay a round trip per good.
+func addRevisions(ctx context.Context, tx pgx.Tx, action entity.EventAction, goods []entity.Goods) error {
+	if len(goods) == 0 {
+		return nil
+	}
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{"goods_revisions"}, revisionColumns,
+		pgx.CopyFromSlice(len(goods), func(i int) ([]any, error) {
+			data, err := json.Marshal(goods[i])
+			if err != nil {
+				return nil, fmt.Errorf("failed marshal revision: %w", err)
+			}
+			return []any{goods[i].Id, goods[i].Version, action, data}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed add revisions: %w", err)
+	}
+	return nil
+}
+
+func scanRevision(row pgx.Row) (entity.GoodsRevision, error) {
+	var rev entity.GoodsRevision
+	var data []byte
+	err := row.Scan(&rev.Revision, &rev.Action, &rev.RevertedFrom, &data, &rev.CreatedAt)
+	if err != nil {
+		return rev, err
+	}
+	if err = json.Unmarshal(data, &rev.Goods); err != nil {
+		return rev, fmt.Errorf("failed unmarshal revision: %w", err)
+	}
+	return rev, nil
+}
+
+// GetItemRevisions returns the revisions of a good oldest first.
+func (r *PgPool) GetItemRevisions(ctx context.Context, id int) ([]entity.GoodsRevision, error) {
+	rows, err := r.db.Query(ctx, queryGetRevisions, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed get revisions: %w", err)
+	}
+	defer rows.Close()
+	res := make([]entity.GoodsRevision, 0)
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed get revisions: %w", err)
+		}
+		res = append(res, rev)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed get revisions: %w", err)
+	}
+	if len(res) > 0 {
+		return res, nil
+	}
+	var exists bool
+	err = r.db.QueryRow(ctx, queryItemExists, id).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed get revisions: %w", err)
+	}
+	if !exists {
+		return nil, entity.ErrNotFound
+	}
+	return res, nil
+}
+
+// RevertItem writes the content of the revision back as a new update, the
+// version check follows PatchItem. Reverting to the current content changes
+// nothing and records no revision. The good is returned as it was before.
+func (r *PgPool) RevertItem(ctx context.Context, item *entity.Goods, revision int) (entity.Goods, entity.GoodsPatch, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.ReadCommitted,
+	})
+	if err != nil {
+		return entity.Goods{}, entity.GoodsPatch{}, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	_, err = lockItemProject(ctx, tx, item.Id)
+	if err != nil {
+		return entity.Goods{}, entity.GoodsPatch{}, fmt.Errorf("failed revert item: %w", err)
+	}
+
+	current, err := currentItem(ctx, tx, item)
+	if err != nil {
+		return entity.Goods{}, entity.GoodsPatch{}, fmt.Errorf("failed revert item: %w", err)
+	}
+	rev, err := scanRevision(tx.QueryRow(ctx, queryGetRevision, item.Id, revision))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrRevisionNotFound
+		}
+		return entity.Goods{}, entity.GoodsPatch{}, fmt.Errorf("failed revert item: %w", err)
+	}
+	changes := rev.RevertPatch(current)
+	if changes.Empty() {
+		*item = current
+		return current, changes, nil
+	}
+	err = patchItemTx(ctx, tx, item, changes, &rev.Revision)
+	if err != nil {
+		return entity.Goods{}, entity.GoodsPatch{}, fmt.Errorf("failed revert item: %w", err)
+	}
+	return current, changes, nil
+}
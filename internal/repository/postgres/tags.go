@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+const (
+	tagColumns = `id, name, created_at`
+
+	queryGetTags   = `SELECT ` + tagColumns + ` FROM tags ORDER BY name`
+	queryGetTag    = `SELECT ` + tagColumns + ` FROM tags WHERE id = $1`
+	queryCreateTag = `INSERT INTO tags (name) VALUES ($1)
+	ON CONFLICT (name) DO NOTHING
+	RETURNING ` + tagColumns
+	queryTagNameTaken = `SELECT EXISTS(SELECT 1 FROM tags WHERE name = $1 AND id <> $2)`
+	queryRenameTag    = `UPDATE tags SET name = $1 WHERE id = $2 RETURNING ` + tagColumns
+	queryDeleteTag    = `DELETE FROM tags WHERE id = $1 RETURNING id`
+	queryTaggedGoods  = `SELECT goods_id FROM goods_tags WHERE tag_id = $1`
+	queryEnsureTags   = `INSERT INTO tags (name) SELECT unnest($1::text[])
+	ON CONFLICT (name) DO NOTHING`
+	queryClearGoodsTags = `DELETE FROM goods_tags WHERE goods_id = $1`
+	querySetGoodsTags   = `INSERT INTO goods_tags (goods_id, tag_id)
+	SELECT $1, id FROM tags WHERE name = ANY($2)`
+	queryGetGoodsLocked = `SELECT ` + goodsColumns + ` FROM GOODS WHERE id = ANY($1) ORDER BY id FOR UPDATE`
+	// queryTouchGoods bumps the version of goods whose tag list changed
+	queryTouchGoods = `UPDATE GOODS SET ` + bumpVersion + ` WHERE id = ANY($1)
+	RETURNING ` + goodsColumns
+)
+
+func scanTag(row pgx.Row) (entity.Tag, error) {
+	var tag entity.Tag
+	err := row.Scan(&tag.Id, &tag.Name, &tag.CreatedAt)
+	return tag, err
+}
+
+func (r *PgPool) GetTags(ctx context.Context) ([]entity.Tag, error) {
+	rows, err := r.db.Query(ctx, queryGetTags)
+	if err != nil {
+		return nil, fmt.Errorf("failed get tags: %w", err)
+	}
+	defer rows.Close()
+	res := make([]entity.Tag, 0)
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed parse into sturct: %w", err)
+		}
+		res = append(res, tag)
+	}
+	return res, rows.Err()
+}
+
+func (r *PgPool) GetTag(ctx context.Context, id int) (*entity.Tag, error) {
+	tag, err := scanTag(r.db.QueryRow(ctx, queryGetTag, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed get tag: %w", err)
+	}
+	return &tag, nil
+}
+
+func (r *PgPool) CreateTag(ctx context.Context, tag *entity.Tag) error {
+	created, err := scanTag(r.db.QueryRow(ctx, queryCreateTag, tag.Name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrTagExists
+		}
+		return fmt.Errorf("failed create tag: %w", err)
+	}
+	*tag = created
+	return nil
+}
+
+// RenameTag returns the goods carrying the tag, their versions are bumped as
+// their tag lists change. A tag carried by goods of an archived project is
+// not renamed, ErrProjectArchived is returned.
+func (r *PgPool) RenameTag(ctx context.Context, tag *entity.Tag) ([]entity.GoodsChange, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.Serializable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	var taken bool
+	err = tx.QueryRow(ctx, queryTagNameTaken, tag.Name, tag.Id).Scan(&taken)
+	if err != nil {
+		return nil, fmt.Errorf("failed check tag name: %w", err)
+	}
+	if taken {
+		err = entity.ErrTagExists
+		return nil, fmt.Errorf("failed rename tag: %w", err)
+	}
+	prev, err := taggedGoods(ctx, tx, tag.Id)
+	if err != nil {
+		return nil, err
+	}
+	renamed, err := scanTag(tx.QueryRow(ctx, queryRenameTag, tag.Name, tag.Id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed rename tag: %w", err)
+	}
+	goods, err := touchGoods(ctx, tx, prev)
+	if err != nil {
+		return nil, err
+	}
+	*tag = renamed
+	return goods, nil
+}
+
+// DeleteTag returns the goods that lost the tag, with their new tag lists.
+// Like RenameTag it refuses tags carried by goods of archived projects.
+func (r *PgPool) DeleteTag(ctx context.Context, id int) ([]entity.GoodsChange, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.Serializable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	prev, err := taggedGoods(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.QueryRow(ctx, queryDeleteTag, id).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = entity.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed delete tag: %w", err)
+	}
+	goods, err := touchGoods(ctx, tx, prev)
+	if err != nil {
+		return nil, err
+	}
+	return goods, nil
+}
+
+// SetItemTags replaces the tags of the good, unknown names are created.
+func (r *PgPool) SetItemTags(ctx context.Context, id int, tags []string) (*entity.GoodsChange, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel: pgx.ReadCommitted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+
+	defer execTx(ctx, tx, &err)
+
+	_, err = lockItemProject(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed set item tags: %w", err)
+	}
+	prev, err := lockGoods(ctx, tx, []int{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed set item tags: %w", err)
+	}
+	_, err = tx.Exec(ctx, queryEnsureTags, tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed create tags: %w", err)
+	}
+	_, err = tx.Exec(ctx, queryClearGoodsTags, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed clear item tags: %w", err)
+	}
+	_, err = tx.Exec(ctx, querySetGoodsTags, id, tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed set item tags: %w", err)
+	}
+	goods, err := touchGoods(ctx, tx, prev)
+	if err != nil {
+		return nil, err
+	}
+	return &goods[0], nil
+}
+
+// taggedGoods locks the goods carrying the tag, see lockGoods.
+func taggedGoods(ctx context.Context, tx pgx.Tx, tagId int) ([]entity.Goods, error) {
+	ids, err := queryInts(ctx, tx, queryTaggedGoods, tagId)
+	if err != nil {
+		return nil, fmt.Errorf("failed get tagged goods: %w", err)
+	}
+	prev, err := lockGoods(ctx, tx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed get tagged goods: %w", err)
+	}
+	return prev, nil
+}
+
+// lockGoods reads the goods before their tag lists change, a statement run
+// after the change would already see the new tags. Goods of archived projects
+// are refused, their tag lists are read-only like the rest of them.
+func lockGoods(ctx context.Context, tx pgx.Tx, ids []int) ([]entity.Goods, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := tx.Query(ctx, queryGetGoodsLocked, ids)
+	if err != nil {
+		return nil, err
+	}
+	goods, err := collectGoods(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(goods) == 0 {
+		return nil, entity.ErrNotFound
+	}
+	projects := make([]int, 0, len(goods))
+	for _, item := range goods {
+		projects = append(projects, item.ProjectId)
+	}
+	err = checkWritable(ctx, tx, projects...)
+	if err != nil {
+		return nil, err
+	}
+	return goods, nil
+}
+
+// touchGoods bumps the versions of the goods read by lockGoods and returns
+// them with the rows as they were.
+func touchGoods(ctx context.Context, tx pgx.Tx, prev []entity.Goods) ([]entity.GoodsChange, error) {
+	if len(prev) == 0 {
+		return nil, nil
+	}
+	ids := make([]int, 0, len(prev))
+	for _, item := range prev {
+		ids = append(ids, item.Id)
+	}
+	rows, err := tx.Query(ctx, queryTouchGoods, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed update tagged goods: %w", err)
+	}
+	goods, err := collectGoods(rows)
+	if err == nil {
+		err = addRevisions(ctx, tx, entity.Update, goods)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed update tagged goods: %w", err)
+	}
+	byId := make(map[int]entity.Goods, len(goods))
+	for _, item := range goods {
+		byId[item.Id] = item
+	}
+	res := make([]entity.GoodsChange, 0, len(prev))
+	for _, item := range prev {
+		res = append(res, entity.GoodsChange{Prev: item, Item: byId[item.Id]})
+	}
+	return res, nil
+}
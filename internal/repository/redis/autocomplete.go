@@ -0,0 +1,186 @@
+package redisClient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis"
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+const (
+	autocompletePrefix = "autocomplete:"
+	maxIndexedWords    = 10
+	memberSeparator    = "\x00"
+)
+
+type autocompleteEntry struct {
+	ProjectId int      `json:"project_id,omitempty"`
+	Members   []string `json:"members"`
+}
+
+func autocompleteKey(scope entity.SuggestScope, projectId int) string {
+	if projectId > 0 {
+		return autocompletePrefix + string(scope) + ":project:" + strconv.Itoa(projectId)
+	}
+	return autocompletePrefix + string(scope)
+}
+
+func autocompleteEntriesKey(scope entity.SuggestScope) string {
+	return autocompletePrefix + string(scope) + ":entries"
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// autocompleteMembers indexes every word suffix of the name, so a prefix
+// query matches the beginning of any word. Members sort lexicographically and
+// carry the id and original name after the searchable part.
+func autocompleteMembers(item entity.Suggestion) []string {
+	words := strings.Fields(strings.ToLower(item.Name))
+	if len(words) > maxIndexedWords {
+		words = words[:maxIndexedWords]
+	}
+	suffix := memberSeparator + strconv.Itoa(item.Id) + memberSeparator + item.Name
+	members := make([]string, 0, len(words))
+	for i := range words {
+		members = append(members, strings.Join(words[i:], " ")+suffix)
+	}
+	return members
+}
+
+func parseMember(member string) (entity.Suggestion, error) {
+	parts := strings.SplitN(member, memberSeparator, 3)
+	if len(parts) != 3 {
+		return entity.Suggestion{}, fmt.Errorf("malformed autocomplete member")
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return entity.Suggestion{}, fmt.Errorf("malformed autocomplete member: %w", err)
+	}
+	return entity.Suggestion{Id: id, Name: parts[2]}, nil
+}
+
+func (rc *RedisClient) AutocompleteAdd(scope entity.SuggestScope, projectId int, item entity.Suggestion) error {
+	if err := rc.AutocompleteRemove(scope, item.Id); err != nil {
+		return err
+	}
+	entry := autocompleteEntry{ProjectId: projectId, Members: autocompleteMembers(item)}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("redis failed marshal autocomplete entry: %w", err)
+	}
+	zs := make([]redis.Z, 0, len(entry.Members))
+	for _, m := range entry.Members {
+		zs = append(zs, redis.Z{Member: m})
+	}
+	_, err = rc.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HSet(autocompleteEntriesKey(scope), strconv.Itoa(item.Id), data)
+		if len(zs) == 0 {
+			return nil
+		}
+		pipe.ZAdd(autocompleteKey(scope, 0), zs...)
+		if projectId > 0 {
+			pipe.ZAdd(autocompleteKey(scope, projectId), zs...)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed redis autocomplete add: %w", err)
+	}
+	return nil
+}
+
+func (rc *RedisClient) AutocompleteRemove(scope entity.SuggestScope, id int) error {
+	field := strconv.Itoa(id)
+	data, err := rc.client.HGet(autocompleteEntriesKey(scope), field).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil
+		}
+		return fmt.Errorf("failed redis autocomplete get: %w", err)
+	}
+	var entry autocompleteEntry
+	if err = json.Unmarshal([]byte(data), &entry); err != nil {
+		return fmt.Errorf("redis unmarshal error: %w", err)
+	}
+	members := make([]interface{}, 0, len(entry.Members))
+	for _, m := range entry.Members {
+		members = append(members, m)
+	}
+	_, err = rc.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HDel(autocompleteEntriesKey(scope), field)
+		if len(members) == 0 {
+			return nil
+		}
+		pipe.ZRem(autocompleteKey(scope, 0), members...)
+		if entry.ProjectId > 0 {
+			pipe.ZRem(autocompleteKey(scope, entry.ProjectId), members...)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed redis autocomplete remove: %w", err)
+	}
+	return nil
+}
+
+// AutocompleteRemoveProject drops goods of a deleted project, postgres removes
+// them by cascade so no per item events reach the usecase.
+func (rc *RedisClient) AutocompleteRemoveProject(projectId int) error {
+	members, err := rc.client.ZRange(autocompleteKey(entity.SuggestGoods, projectId), 0, -1).Result()
+	if err != nil {
+		return fmt.Errorf("failed redis autocomplete range: %w", err)
+	}
+	seen := make(map[int]bool, len(members))
+	for _, m := range members {
+		item, err := parseMember(m)
+		if err != nil {
+			return err
+		}
+		if seen[item.Id] {
+			continue
+		}
+		seen[item.Id] = true
+		if err = rc.AutocompleteRemove(entity.SuggestGoods, item.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rc *RedisClient) AutocompleteSearch(scope entity.SuggestScope, prefix string, projectId int, limit int) ([]entity.Suggestion, error) {
+	prefix = normalize(prefix)
+	members, err := rc.client.ZRangeByLex(autocompleteKey(scope, projectId), redis.ZRangeBy{
+		Min:   "[" + prefix,
+		Max:   "[" + prefix + "\xff",
+		Count: int64(limit * maxIndexedWords),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed redis autocomplete search: %w", err)
+	}
+	seen := make(map[int]bool, len(members))
+	res := make([]entity.Suggestion, 0, limit)
+	for _, m := range members {
+		item, err := parseMember(m)
+		if err != nil {
+			return nil, err
+		}
+		if seen[item.Id] {
+			continue
+		}
+		seen[item.Id] = true
+		res = append(res, item)
+		if len(res) == limit {
+			break
+		}
+	}
+	return res, nil
+}
+
+func (rc *RedisClient) AutocompleteClear(scope entity.SuggestScope) error {
+	return rc.deleteByPattern(autocompletePrefix + string(scope) + "*")
+}
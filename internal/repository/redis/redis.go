@@ -10,7 +10,9 @@ import (
 )
 
 const (
-	ttl = 60 * time.Second
+	ttl         = 60 * time.Second
+	cachePrefix = "cache:"
+	scanCount   = 1000
 )
 
 type RedisClient struct {
@@ -30,11 +32,11 @@ func (rc *RedisClient) RedisSetItem(key string, item interface{}) error {
 	if err != nil {
 		return fmt.Errorf("redis failed marshal item: %w", err)
 	}
-	return rc.client.Set(key, data, ttl).Err()
+	return rc.client.Set(cachePrefix+key, data, ttl).Err()
 }
 
 func (rc *RedisClient) RedisGetItem(key string) (*entity.Goods, error) {
-	data, err := rc.client.Get(key).Result()
+	data, err := rc.client.Get(cachePrefix + key).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return nil, err
@@ -49,7 +51,7 @@ func (rc *RedisClient) RedisGetItem(key string) (*entity.Goods, error) {
 }
 
 func (rc *RedisClient) RedisGetItems(key string) ([]entity.Goods, error) {
-	data, err := rc.client.Get(key).Result()
+	data, err := rc.client.Get(cachePrefix + key).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return nil, err
@@ -64,7 +66,7 @@ func (rc *RedisClient) RedisGetItems(key string) ([]entity.Goods, error) {
 }
 
 func (rc *RedisClient) RedisGetProject(key string) (*entity.Project, error) {
-	data, err := rc.client.Get(key).Result()
+	data, err := rc.client.Get(cachePrefix + key).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return nil, err
@@ -79,7 +81,7 @@ func (rc *RedisClient) RedisGetProject(key string) (*entity.Project, error) {
 }
 
 func (rc *RedisClient) RedisGetProjects(key string) ([]entity.Project, error) {
-	data, err := rc.client.Get(key).Result()
+	data, err := rc.client.Get(cachePrefix + key).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return nil, err
@@ -93,6 +95,72 @@ func (rc *RedisClient) RedisGetProjects(key string) ([]entity.Project, error) {
 	return goods, nil
 }
 
+func (rc *RedisClient) RedisGetGoodsPage(key string) (*entity.GoodsResponse, error) {
+	data, err := rc.client.Get(cachePrefix + key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed redis get item: %w", err)
+	}
+	var page entity.GoodsResponse
+	if err = json.Unmarshal([]byte(data), &page); err != nil {
+		return nil, fmt.Errorf("redis unmarshal error: %w", err)
+	}
+	return &page, nil
+}
+
+func (rc *RedisClient) RedisGetProjectsPage(key string) (*entity.ProjectResponse, error) {
+	data, err := rc.client.Get(cachePrefix + key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed redis get item: %w", err)
+	}
+	var page entity.ProjectResponse
+	if err = json.Unmarshal([]byte(data), &page); err != nil {
+		return nil, fmt.Errorf("redis unmarshal error: %w", err)
+	}
+	return &page, nil
+}
+
+func (rc *RedisClient) RedisGetSearchPage(key string) (*entity.GoodsSearchResponse, error) {
+	data, err := rc.client.Get(cachePrefix + key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed redis get item: %w", err)
+	}
+	var page entity.GoodsSearchResponse
+	if err = json.Unmarshal([]byte(data), &page); err != nil {
+		return nil, fmt.Errorf("redis unmarshal error: %w", err)
+	}
+	return &page, nil
+}
+
+// CleanCache drops cached responses only, other keys such as the
+// autocomplete index survive invalidation.
 func (rc *RedisClient) CleanCache() error {
-	return rc.client.FlushAll().Err()
+	return rc.deleteByPattern(cachePrefix + "*")
+}
+
+func (rc *RedisClient) deleteByPattern(pattern string) error {
+	var cursor uint64
+	for {
+		keys, next, err := rc.client.Scan(cursor, pattern, scanCount).Result()
+		if err != nil {
+			return fmt.Errorf("failed redis scan: %w", err)
+		}
+		if len(keys) > 0 {
+			if err = rc.client.Del(keys...).Err(); err != nil {
+				return fmt.Errorf("failed redis del: %w", err)
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
 }
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+// GetAuditEvents is not cached, the log grows with every write.
+func (uc *usecase) GetAuditEvents(ctx context.Context, filter entity.AuditFilter, page entity.Page) (*entity.AuditResponse, error) {
+	return uc.repo.GetEvents(ctx, filter, page)
+}
+
+// GetProjectGoodsAsOf asks Postgres about a project the log knows nothing of
+// by asOf: it is returned as unknown only when it exists and was created
+// before asOf, so it predates the log. Otherwise it did not exist then.
+func (uc *usecase) GetProjectGoodsAsOf(ctx context.Context, projectId int, asOf time.Time, includeRemoved bool) (*entity.ProjectGoodsAsOf, error) {
+	res, err := uc.repo.GetProjectGoodsAsOf(ctx, projectId, asOf, includeRemoved)
+	if err != nil || res.Known {
+		return res, err
+	}
+	project, err := uc.repo.GetProject(ctx, projectId)
+	if err != nil {
+		return nil, err
+	}
+	if project.CreatedAt.After(asOf) {
+		return nil, entity.ErrNotFound
+	}
+	return res, nil
+}
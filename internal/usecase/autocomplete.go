@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/paxaf/HezzlTest/internal/logger"
+)
+
+// Autocomplete serves prefixes from the redis index and tops the result up
+// with postgres trigram matches when the index has fewer than limit hits. The
+// index only knows names written since it was started, and trigrams also
+// cover typos.
+func (uc *usecase) Autocomplete(ctx context.Context, scope entity.SuggestScope, query string, projectId int, limit int) ([]entity.Suggestion, error) {
+	res, err := uc.repo.AutocompleteSearch(scope, query, projectId, limit)
+	if err != nil {
+		logger.Error("error autocomplete search", err)
+	}
+	if len(res) >= limit {
+		return res, nil
+	}
+	var more []entity.Suggestion
+	if scope == entity.SuggestProjects {
+		more, err = uc.repo.SuggestProjects(ctx, query, limit)
+	} else {
+		more, err = uc.repo.SuggestItems(ctx, query, projectId, limit)
+	}
+	if err != nil {
+		if len(res) > 0 {
+			logger.Error("error suggest", err)
+			return res, nil
+		}
+		return nil, err
+	}
+	return mergeSuggestions(res, more, limit), nil
+}
+
+// mergeSuggestions appends the fallback matches missing from the index hits.
+func mergeSuggestions(hits, more []entity.Suggestion, limit int) []entity.Suggestion {
+	seen := make(map[int]bool, len(hits))
+	res := make([]entity.Suggestion, 0, limit)
+	for _, hit := range hits {
+		seen[hit.Id] = true
+		res = append(res, hit)
+	}
+	for _, item := range more {
+		if len(res) >= limit {
+			break
+		}
+		if !seen[item.Id] {
+			seen[item.Id] = true
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+func (uc *usecase) indexItem(item entity.Goods) {
+	var err error
+	if item.Removed {
+		err = uc.repo.AutocompleteRemove(entity.SuggestGoods, item.Id)
+	} else {
+		err = uc.repo.AutocompleteAdd(entity.SuggestGoods, item.ProjectId, entity.Suggestion{Id: item.Id, Name: item.Name})
+	}
+	if err != nil {
+		logger.Error("error index goods", err)
+	}
+}
+
+func (uc *usecase) indexProject(item entity.Project) {
+	err := uc.repo.AutocompleteAdd(entity.SuggestProjects, 0, entity.Suggestion{Id: item.Id, Name: item.Name})
+	if err != nil {
+		logger.Error("error index project", err)
+	}
+}
+
+func (uc *usecase) unindexProject(id int) {
+	err := uc.repo.AutocompleteRemove(entity.SuggestProjects, id)
+	if err == nil {
+		err = uc.repo.AutocompleteRemoveProject(id)
+	}
+	if err != nil {
+		logger.Error("error unindex project", err)
+	}
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeSuggestions(t *testing.T) {
+	a := entity.Suggestion{Id: 1, Name: "apple"}
+	b := entity.Suggestion{Id: 2, Name: "apricot"}
+	c := entity.Suggestion{Id: 3, Name: "aprikose"}
+	tests := []struct {
+		name  string
+		hits  []entity.Suggestion
+		more  []entity.Suggestion
+		limit int
+		want  []entity.Suggestion
+	}{
+		{"empty index", nil, []entity.Suggestion{a, b}, 10, []entity.Suggestion{a, b}},
+		{"hits first", []entity.Suggestion{b}, []entity.Suggestion{a}, 10, []entity.Suggestion{b, a}},
+		{"skips duplicates", []entity.Suggestion{a}, []entity.Suggestion{a, b}, 10, []entity.Suggestion{a, b}},
+		{"stops at limit", []entity.Suggestion{a}, []entity.Suggestion{b, c}, 2, []entity.Suggestion{a, b}},
+		{"nothing found", nil, nil, 10, []entity.Suggestion{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mergeSuggestions(tt.hits, tt.more, tt.limit))
+		})
+	}
+}
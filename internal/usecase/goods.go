@@ -7,12 +7,12 @@ import (
 	"github.com/paxaf/HezzlTest/internal/logger"
 )
 
-func (uc *usecase) GetAllItems(ctx context.Context, key string) ([]entity.Goods, error) {
-	res, err := uc.repo.RedisGetItems(key)
+func (uc *usecase) GetAllItems(ctx context.Context, key string, filter entity.GoodsFilter, page entity.Page) (*entity.GoodsResponse, error) {
+	res, err := uc.repo.RedisGetGoodsPage(key)
 	if err == nil {
 		return res, nil
 	}
-	res, err = uc.repo.GetAllItems(ctx)
+	res, err = uc.repo.GetAllItems(ctx, filter, page)
 	if err != nil {
 		return nil, err
 	}
@@ -23,6 +23,11 @@ func (uc *usecase) GetAllItems(ctx context.Context, key string) ([]entity.Goods,
 	return res, nil
 }
 
+// ExportItems bypasses the cache, exports are too large to keep in redis.
+func (uc *usecase) ExportItems(ctx context.Context, filter entity.GoodsFilter, fn func(entity.Goods) error) error {
+	return uc.repo.StreamItems(ctx, filter, fn)
+}
+
 func (uc *usecase) GetItem(ctx context.Context, key string, goodsId int) (*entity.Goods, error) {
 	res, err := uc.repo.RedisGetItem(key)
 	if err == nil {
@@ -39,12 +44,28 @@ func (uc *usecase) GetItem(ctx context.Context, key string, goodsId int) (*entit
 	return res, nil
 }
 
-func (uc *usecase) GetItemsByProject(ctx context.Context, key string, projectId int) ([]entity.Goods, error) {
-	res, err := uc.repo.RedisGetItems(key)
+func (uc *usecase) GetItemsByProject(ctx context.Context, key string, projectId int, includeRemoved bool, tag string, page entity.Page) (*entity.GoodsResponse, error) {
+	res, err := uc.repo.RedisGetGoodsPage(key)
+	if err == nil {
+		return res, nil
+	}
+	res, err = uc.repo.GetItemsByProject(ctx, projectId, includeRemoved, tag, page)
+	if err != nil {
+		return nil, err
+	}
+	err = uc.repo.RedisSetItem(key, res)
+	if err != nil {
+		logger.Error("error set cache", err)
+	}
+	return res, nil
+}
+
+func (uc *usecase) GetItemsByName(ctx context.Context, key string, name string, includeRemoved bool, tag string, page entity.Page) (*entity.GoodsResponse, error) {
+	res, err := uc.repo.RedisGetGoodsPage(key)
 	if err == nil {
 		return res, nil
 	}
-	res, err = uc.repo.GetItemsByProject(ctx, projectId)
+	res, err = uc.repo.GetItemsByName(ctx, name, includeRemoved, tag, page)
 	if err != nil {
 		return nil, err
 	}
@@ -55,12 +76,12 @@ func (uc *usecase) GetItemsByProject(ctx context.Context, key string, projectId
 	return res, nil
 }
 
-func (uc *usecase) GetItemsByName(ctx context.Context, key string, name string) ([]entity.Goods, error) {
-	res, err := uc.repo.RedisGetItems(key)
+func (uc *usecase) SearchItems(ctx context.Context, key string, query string, includeRemoved bool, page entity.Page) (*entity.GoodsSearchResponse, error) {
+	res, err := uc.repo.RedisGetSearchPage(key)
 	if err == nil {
 		return res, nil
 	}
-	res, err = uc.repo.GetItemsByName(ctx, name)
+	res, err = uc.repo.SearchItems(ctx, query, includeRemoved, page)
 	if err != nil {
 		return nil, err
 	}
@@ -80,20 +101,55 @@ func (uc *usecase) CreateItem(ctx context.Context, item *entity.Goods) error {
 	if err != nil {
 		return err
 	}
+	uc.indexItem(*item)
 	uc.repo.LogEvent(entity.NewGoodEvent(entity.Create, *item))
 	return nil
 }
 
+func (uc *usecase) CreateItems(ctx context.Context, items []entity.Goods) ([]entity.Goods, error) {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return nil, err
+	}
+	res, err := uc.repo.CreateItems(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range res {
+		uc.indexItem(item)
+		uc.repo.LogEvent(entity.NewGoodEvent(entity.Create, item))
+	}
+	return res, nil
+}
+
 func (uc *usecase) UpdateItem(ctx context.Context, item *entity.Goods) error {
 	err := uc.repo.CleanCache()
 	if err != nil {
 		return err
 	}
-	err = uc.repo.UpdateItem(ctx, item)
+	prev, err := uc.repo.UpdateItem(ctx, item)
+	if err != nil {
+		return err
+	}
+	uc.indexItem(*item)
+	uc.repo.LogEvent(entity.NewGoodUpdateEvent(prev, *item))
+	return nil
+}
+
+func (uc *usecase) PatchItem(ctx context.Context, item *entity.Goods, patch entity.GoodsPatch) error {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return err
+	}
+	prev, changes, err := uc.repo.PatchItem(ctx, item, patch)
 	if err != nil {
 		return err
 	}
-	uc.repo.LogEvent(entity.NewGoodEvent(entity.Update, *item))
+	if changes.Empty() {
+		return nil
+	}
+	uc.indexItem(*item)
+	uc.repo.LogEvent(entity.NewGoodPatchEvent(prev, *item, changes))
 	return nil
 }
 
@@ -102,10 +158,89 @@ func (uc *usecase) DeleteItem(ctx context.Context, id int) error {
 	if err != nil {
 		return err
 	}
-	err = uc.repo.DeleteItem(ctx, id)
+	item, err := uc.repo.DeleteItem(ctx, id)
 	if err != nil {
 		return err
 	}
-	uc.repo.LogEvent(entity.NewGoodEvent(entity.Delete, entity.Goods{Id: id}))
+	uc.indexItem(*item)
+	uc.repo.LogEvent(entity.NewGoodEvent(entity.Delete, *item))
 	return nil
 }
+
+func (uc *usecase) UpdateItems(ctx context.Context, sel entity.GoodsSelector, patch entity.GoodsPatch) ([]entity.Goods, error) {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return nil, err
+	}
+	res, err := uc.repo.UpdateItems(ctx, sel, patch)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range res {
+		uc.indexItem(change.Item)
+		uc.repo.LogEvent(entity.NewGoodUpdateEvent(change.Prev, change.Item))
+	}
+	return entity.ChangedGoods(res), nil
+}
+
+func (uc *usecase) DeleteItems(ctx context.Context, sel entity.GoodsSelector) ([]entity.Goods, error) {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return nil, err
+	}
+	res, err := uc.repo.DeleteItems(ctx, sel)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range res {
+		uc.indexItem(item)
+		uc.repo.LogEvent(entity.NewGoodEvent(entity.Delete, item))
+	}
+	return res, nil
+}
+
+func (uc *usecase) RestoreItem(ctx context.Context, id int) (*entity.Goods, error) {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return nil, err
+	}
+	item, err := uc.repo.RestoreItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	uc.indexItem(*item)
+	uc.repo.LogEvent(entity.NewGoodEvent(entity.Restore, *item))
+	return item, nil
+}
+
+func (uc *usecase) ReprioritizeItem(ctx context.Context, id int, priority int) ([]entity.Goods, error) {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return nil, err
+	}
+	res, err := uc.repo.ReprioritizeItem(ctx, id, priority)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range res {
+		uc.repo.LogEvent(entity.NewGoodUpdateEvent(change.Prev, change.Item))
+	}
+	return entity.ChangedGoods(res), nil
+}
+
+func (uc *usecase) MoveItem(ctx context.Context, id int, projectId int, priority int) (*entity.MoveResult, error) {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return nil, err
+	}
+	res, err := uc.repo.MoveItem(ctx, id, projectId, priority)
+	if err != nil {
+		return nil, err
+	}
+	uc.indexItem(res.Item)
+	uc.repo.LogEvent(entity.NewGoodMoveEvent(res.Item, res.FromProjectId))
+	for i, item := range res.Shifted {
+		uc.repo.LogEvent(entity.NewGoodUpdateEvent(res.ShiftedPrev[i], item))
+	}
+	return res, nil
+}
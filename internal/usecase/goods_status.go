@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+// goodsTransitions lists the statuses a good may be moved to by hand. Any
+// status but expired can be expired early, an expired good goes back to draft
+// to be scheduled again.
+var goodsTransitions = map[entity.GoodsStatus][]entity.GoodsStatus{
+	entity.GoodsDraft:   {entity.GoodsActive, entity.GoodsExpired},
+	entity.GoodsActive:  {entity.GoodsPaused, entity.GoodsExpired},
+	entity.GoodsPaused:  {entity.GoodsActive, entity.GoodsExpired},
+	entity.GoodsExpired: {entity.GoodsDraft},
+}
+
+// SetItemStatus also refuses to activate a good outside its window, the
+// scheduler would flip it back on the next tick.
+func (uc *usecase) SetItemStatus(ctx context.Context, id int, status entity.GoodsStatus) (*entity.Goods, error) {
+	item, err := uc.repo.GetItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	from := item.Status
+	if !slices.Contains(goodsTransitions[from], status) {
+		return nil, &entity.TransitionError{Entity: "good", From: string(from), To: string(status)}
+	}
+	if status == entity.GoodsActive && !item.WindowOpen(time.Now()) {
+		return nil, &entity.TransitionError{
+			Entity: "good",
+			From:   string(from),
+			To:     string(status),
+			Reason: "outside of the activation window",
+		}
+	}
+	err = uc.repo.CleanCache()
+	if err != nil {
+		return nil, err
+	}
+	item, err = uc.repo.SetItemStatus(ctx, id, from, status)
+	if err != nil {
+		return nil, err
+	}
+	uc.repo.LogEvent(entity.NewGoodStatusEvent(*item, from))
+	return item, nil
+}
+
+// SetItemWindow also logs the status change when the new window moved the
+// good to another status.
+func (uc *usecase) SetItemWindow(ctx context.Context, id int, from, to *time.Time) (*entity.Goods, error) {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return nil, err
+	}
+	change, err := uc.repo.SetItemWindow(ctx, id, from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	uc.repo.LogEvent(entity.NewGoodUpdateEvent(change.Prev, change.Item))
+	if change.Item.Status != change.Prev.Status {
+		uc.repo.LogEvent(entity.NewGoodStatusEvent(change.Item, change.Prev.Status))
+	}
+	return &change.Item, nil
+}
+
+// ApplyGoodsWindows activates and expires the goods whose window opened or
+// closed by now and returns the number of goods changed.
+func (uc *usecase) ApplyGoodsWindows(ctx context.Context, now time.Time) (int, error) {
+	activated, err := uc.repo.ActivateDueItems(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	expired, err := uc.repo.ExpireDueItems(ctx, now)
+	if err != nil {
+		return len(activated), err
+	}
+	changes := append(activated, expired...)
+	if len(changes) == 0 {
+		return 0, nil
+	}
+	err = uc.repo.CleanCache()
+	if err != nil {
+		return len(changes), err
+	}
+	for _, change := range changes {
+		uc.repo.LogEvent(entity.NewGoodStatusEvent(change.Item, change.From))
+	}
+	return len(changes), nil
+}
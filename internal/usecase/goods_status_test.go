@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/paxaf/HezzlTest/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goodsStatusRepo records the writes of SetItemStatus, any other call hits
+// the nil Repository and panics.
+type goodsStatusRepo struct {
+	repository.Repository
+	item   entity.Goods
+	writes []entity.GoodsStatus
+	events []entity.Event
+}
+
+func (r *goodsStatusRepo) GetItem(ctx context.Context, id int) (*entity.Goods, error) {
+	item := r.item
+	return &item, nil
+}
+
+func (r *goodsStatusRepo) CleanCache() error {
+	return nil
+}
+
+func (r *goodsStatusRepo) SetItemStatus(ctx context.Context, id int, from, to entity.GoodsStatus) (*entity.Goods, error) {
+	r.writes = append(r.writes, to)
+	item := r.item
+	item.Status = to
+	return &item, nil
+}
+
+func (r *goodsStatusRepo) SetItemWindow(ctx context.Context, id int, from, to *time.Time, now time.Time) (*entity.GoodsChange, error) {
+	item := r.item
+	item.ActiveFrom, item.ActiveTo = from, to
+	item.Status = item.WindowStatus(now)
+	return &entity.GoodsChange{Prev: r.item, Item: item}, nil
+}
+
+func (r *goodsStatusRepo) LogEvent(event entity.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestSetItemStatus(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	tests := []struct {
+		name       string
+		from, to   entity.GoodsStatus
+		activeFrom *time.Time
+		allowed    bool
+	}{
+		{"draft to active", entity.GoodsDraft, entity.GoodsActive, nil, true},
+		{"draft to paused", entity.GoodsDraft, entity.GoodsPaused, nil, false},
+		{"draft to expired", entity.GoodsDraft, entity.GoodsExpired, nil, true},
+		{"active to paused", entity.GoodsActive, entity.GoodsPaused, nil, true},
+		{"active to draft", entity.GoodsActive, entity.GoodsDraft, nil, false},
+		{"paused to active", entity.GoodsPaused, entity.GoodsActive, nil, true},
+		{"paused to expired", entity.GoodsPaused, entity.GoodsExpired, nil, true},
+		{"expired to draft", entity.GoodsExpired, entity.GoodsDraft, nil, true},
+		{"expired to active", entity.GoodsExpired, entity.GoodsActive, nil, false},
+		{"active before window", entity.GoodsDraft, entity.GoodsActive, &future, false},
+		{"paused before window", entity.GoodsPaused, entity.GoodsActive, &future, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &goodsStatusRepo{item: entity.Goods{Id: 1, ProjectId: 1, Status: tt.from, ActiveFrom: tt.activeFrom}}
+			item, err := New(repo).SetItemStatus(context.Background(), 1, tt.to)
+			if !tt.allowed {
+				var transition *entity.TransitionError
+				require.ErrorAs(t, err, &transition)
+				assert.Equal(t, string(tt.from), transition.From)
+				assert.Equal(t, string(tt.to), transition.To)
+				assert.Empty(t, repo.writes)
+				assert.Empty(t, repo.events)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, item.Status)
+			assert.Equal(t, []entity.GoodsStatus{tt.to}, repo.writes)
+			require.Len(t, repo.events, 1)
+			assert.Equal(t, entity.Status, repo.events[0].Action)
+			payload := repo.events[0].Payload.(entity.GoodEventPayload)
+			assert.Equal(t, tt.from, *payload.FromStatus)
+		})
+	}
+}
+
+func TestSetItemWindow(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	tests := []struct {
+		name   string
+		from   *time.Time
+		fields []string
+		status bool
+	}{
+		{"same status", nil, []string{}, false},
+		{"window opens later", &future, []string{"active_from", "status"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &goodsStatusRepo{item: entity.Goods{Id: 1, ProjectId: 1, Status: entity.GoodsActive}}
+			_, err := New(repo).SetItemWindow(context.Background(), 1, tt.from, nil)
+			require.NoError(t, err)
+			require.NotEmpty(t, repo.events)
+			update := repo.events[0]
+			assert.Equal(t, entity.Update, update.Action)
+			assert.NotNil(t, update.Before)
+			assert.Equal(t, tt.fields, update.ChangedFields)
+			if tt.status {
+				require.Len(t, repo.events, 2)
+				assert.Equal(t, entity.Status, repo.events[1].Action)
+			} else {
+				assert.Len(t, repo.events, 1)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/paxaf/HezzlTest/internal/logger"
+)
+
+// importQueueSize bounds the jobs waiting for RunImports, ImportItems blocks
+// once it is full.
+const importQueueSize = 16
+
+type importTask struct {
+	job   entity.ImportJob
+	items []entity.Goods
+}
+
+// ImportItems records an import job and queues it for RunImports, the
+// returned job is polled through GetImportJob. With upsert the last row wins
+// when the file repeats a name.
+func (uc *usecase) ImportItems(ctx context.Context, projectId int, items []entity.Goods, upsert bool) (*entity.ImportJob, error) {
+	project, err := uc.repo.GetProject(ctx, projectId)
+	if err != nil {
+		return nil, err
+	}
+	if project.Status == entity.ProjectArchived {
+		return nil, entity.ErrProjectArchived
+	}
+	if upsert {
+		items = lastByName(items)
+	}
+	job := &entity.ImportJob{
+		ProjectId: projectId,
+		Status:    entity.ImportPending,
+		Upsert:    upsert,
+		Total:     len(items),
+	}
+	err = uc.repo.CreateImportJob(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case uc.imports <- importTask{job: *job, items: items}:
+	case <-ctx.Done():
+		job.Status = entity.ImportFailed
+		job.Error = ctx.Err().Error()
+		uc.saveImportJob(context.WithoutCancel(ctx), job)
+		return nil, ctx.Err()
+	}
+	return job, nil
+}
+
+// RunImports runs queued jobs one at a time until ctx is cancelled. A job
+// cut off by the cancel is failed, jobs still queued stay pending until
+// FailUnfinishedImports runs on the next start.
+func (uc *usecase) RunImports(ctx context.Context) {
+	for {
+		select {
+		case task := <-uc.imports:
+			uc.runImport(ctx, task.job, task.items)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// FailUnfinishedImports fails the jobs a previous run did not finish, their
+// rows were never stored.
+func (uc *usecase) FailUnfinishedImports(ctx context.Context) error {
+	n, err := uc.repo.FailUnfinishedImportJobs(ctx, entity.ImportInterrupted)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Info("Unfinished import jobs failed", "count", n)
+	}
+	return nil
+}
+
+func (uc *usecase) GetImportJob(ctx context.Context, id int) (*entity.ImportJob, error) {
+	return uc.repo.GetImportJob(ctx, id)
+}
+
+// runImport writes items chunk by chunk, each chunk is its own transaction
+// and progress is saved after every one of them.
+func (uc *usecase) runImport(ctx context.Context, job entity.ImportJob, items []entity.Goods) {
+	job.Status = entity.ImportRunning
+	uc.saveImportJob(ctx, &job)
+
+	var err error
+	for start := 0; start < len(items); start += entity.ImportChunkSize {
+		end := min(start+entity.ImportChunkSize, len(items))
+		err = uc.importChunk(ctx, &job, items[start:end])
+		if err != nil {
+			break
+		}
+		job.Processed = end
+		uc.saveImportJob(ctx, &job)
+	}
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		job.Status = entity.ImportFailed
+		job.Error = entity.ImportInterrupted
+	case err != nil:
+		logger.Error("error import goods", err)
+		job.Status = entity.ImportFailed
+		job.Error = err.Error()
+	default:
+		job.Status = entity.ImportDone
+	}
+	if err = uc.repo.CleanCache(); err != nil {
+		logger.Error("error clean cache", err)
+	}
+	uc.saveImportJob(context.WithoutCancel(ctx), &job)
+}
+
+func (uc *usecase) importChunk(ctx context.Context, job *entity.ImportJob, items []entity.Goods) error {
+	var created []entity.Goods
+	var updated []entity.GoodsChange
+	var err error
+	if job.Upsert {
+		created, updated, err = uc.repo.UpsertItems(ctx, job.ProjectId, items)
+	} else {
+		created, err = uc.repo.CreateItems(ctx, items)
+	}
+	if err != nil {
+		return err
+	}
+	for _, item := range created {
+		uc.indexItem(item)
+		uc.repo.LogEvent(entity.NewGoodEvent(entity.Create, item))
+	}
+	for _, change := range updated {
+		uc.repo.LogEvent(entity.NewGoodUpdateEvent(change.Prev, change.Item))
+	}
+	job.Created += len(created)
+	job.Updated += len(updated)
+	return nil
+}
+
+func (uc *usecase) saveImportJob(ctx context.Context, job *entity.ImportJob) {
+	if err := uc.repo.UpdateImportJob(ctx, job); err != nil {
+		logger.Error("error save import job", err)
+	}
+}
+
+func lastByName(items []entity.Goods) []entity.Goods {
+	pos := make(map[string]int, len(items))
+	res := make([]entity.Goods, 0, len(items))
+	for _, item := range items {
+		if i, ok := pos[item.Name]; ok {
+			res[i] = item
+			continue
+		}
+		pos[item.Name] = len(res)
+		res = append(res, item)
+	}
+	return res
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+// projectTransitions lists the statuses a project may move to. Archived is
+// final, a campaign is brought back by cloning it.
+var projectTransitions = map[entity.ProjectStatus][]entity.ProjectStatus{
+	entity.ProjectDraft:    {entity.ProjectActive, entity.ProjectArchived},
+	entity.ProjectActive:   {entity.ProjectPaused, entity.ProjectArchived},
+	entity.ProjectPaused:   {entity.ProjectActive, entity.ProjectArchived},
+	entity.ProjectArchived: {},
+}
+
+func (uc *usecase) SetProjectStatus(ctx context.Context, id int, status entity.ProjectStatus) (*entity.Project, error) {
+	project, err := uc.repo.GetProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	from := project.Status
+	if !slices.Contains(projectTransitions[from], status) {
+		return nil, &entity.TransitionError{Entity: "project", From: string(from), To: string(status)}
+	}
+	err = uc.repo.CleanCache()
+	if err != nil {
+		return nil, err
+	}
+	project, err = uc.repo.SetProjectStatus(ctx, id, from, status)
+	if err != nil {
+		return nil, err
+	}
+	uc.repo.LogEvent(entity.NewProjectStatusEvent(*project, from))
+	return project, nil
+}
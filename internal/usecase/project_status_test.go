@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+	"github.com/paxaf/HezzlTest/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// projectStatusRepo records the writes of SetProjectStatus, any other call
+// hits the nil Repository and panics.
+type projectStatusRepo struct {
+	repository.Repository
+	project entity.Project
+	writes  []entity.ProjectStatus
+	events  []entity.Event
+}
+
+func (r *projectStatusRepo) GetProject(ctx context.Context, id int) (*entity.Project, error) {
+	project := r.project
+	return &project, nil
+}
+
+func (r *projectStatusRepo) CleanCache() error {
+	return nil
+}
+
+func (r *projectStatusRepo) SetProjectStatus(ctx context.Context, id int, from, to entity.ProjectStatus) (*entity.Project, error) {
+	r.writes = append(r.writes, to)
+	project := r.project
+	project.Status = to
+	return &project, nil
+}
+
+func (r *projectStatusRepo) LogEvent(event entity.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestSetProjectStatus(t *testing.T) {
+	tests := []struct {
+		from, to entity.ProjectStatus
+		allowed  bool
+	}{
+		{entity.ProjectDraft, entity.ProjectActive, true},
+		{entity.ProjectDraft, entity.ProjectPaused, false},
+		{entity.ProjectDraft, entity.ProjectArchived, true},
+		{entity.ProjectActive, entity.ProjectPaused, true},
+		{entity.ProjectActive, entity.ProjectDraft, false},
+		{entity.ProjectActive, entity.ProjectActive, false},
+		{entity.ProjectPaused, entity.ProjectActive, true},
+		{entity.ProjectPaused, entity.ProjectArchived, true},
+		{entity.ProjectArchived, entity.ProjectActive, false},
+		{entity.ProjectArchived, entity.ProjectDraft, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			repo := &projectStatusRepo{project: entity.Project{Id: 1, Name: "Project", Status: tt.from}}
+			project, err := New(repo).SetProjectStatus(context.Background(), 1, tt.to)
+			if !tt.allowed {
+				var transition *entity.TransitionError
+				require.ErrorAs(t, err, &transition)
+				assert.Equal(t, string(tt.from), transition.From)
+				assert.Equal(t, string(tt.to), transition.To)
+				assert.Empty(t, repo.writes)
+				assert.Empty(t, repo.events)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, project.Status)
+			assert.Equal(t, []entity.ProjectStatus{tt.to}, repo.writes)
+			require.Len(t, repo.events, 1)
+			assert.Equal(t, entity.Status, repo.events[0].Action)
+			payload := repo.events[0].Payload.(entity.ProjectEventPayload)
+			assert.Equal(t, tt.from, payload.FromStatus)
+			assert.Equal(t, tt.to, payload.Status)
+		})
+	}
+}
@@ -23,12 +23,17 @@ func (uc *usecase) GetProject(ctx context.Context, key string, id int) (*entity.
 	return res, nil
 }
 
-func (uc *usecase) GetProjects(ctx context.Context, key string) ([]entity.Project, error) {
-	res, err := uc.repo.RedisGetProjects(key)
+// GetProjectSummary is not cached, goods writes would have to invalidate it.
+func (uc *usecase) GetProjectSummary(ctx context.Context, id int) (*entity.ProjectSummary, error) {
+	return uc.repo.GetProjectSummary(ctx, id)
+}
+
+func (uc *usecase) GetProjects(ctx context.Context, key string, filter entity.ProjectFilter, page entity.Page) (*entity.ProjectResponse, error) {
+	res, err := uc.repo.RedisGetProjectsPage(key)
 	if err == nil {
 		return res, nil
 	}
-	res, err = uc.repo.GetProjects(ctx)
+	res, err = uc.repo.GetProjects(ctx, filter, page)
 	if err != nil {
 		return nil, err
 	}
@@ -48,6 +53,7 @@ func (uc *usecase) UpdateProject(ctx context.Context, item *entity.Project) erro
 	if err != nil {
 		return err
 	}
+	uc.indexProject(*item)
 	uc.repo.LogEvent(entity.NewProjectEvent(entity.Update, *item))
 	return nil
 }
@@ -61,19 +67,42 @@ func (uc *usecase) AddProject(ctx context.Context, item *entity.Project) error {
 	if err != nil {
 		return err
 	}
+	uc.indexProject(*item)
 	uc.repo.LogEvent(entity.NewProjectEvent(entity.Update, *item))
 	return nil
 }
 
-func (uc *usecase) DeleteProject(ctx context.Context, id int) error {
+func (uc *usecase) DeleteProject(ctx context.Context, id int, force bool) error {
 	err := uc.repo.CleanCache()
 	if err != nil {
 		return err
 	}
-	err = uc.repo.DeleteProject(ctx, id)
+	project, goods, err := uc.repo.DeleteProject(ctx, id, force)
 	if err != nil {
 		return err
 	}
-	uc.repo.LogEvent(entity.NewProjectEvent(entity.Update, entity.Project{Id: id}))
+	uc.unindexProject(id)
+	uc.repo.LogEvent(entity.NewProjectEvent(entity.Delete, *project))
+	for _, item := range goods {
+		uc.repo.LogEvent(entity.NewGoodEvent(entity.Delete, item))
+	}
 	return nil
 }
+
+func (uc *usecase) CloneProject(ctx context.Context, id int, name string) (*entity.ProjectClone, error) {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return nil, err
+	}
+	project, goods, err := uc.repo.CloneProject(ctx, id, name)
+	if err != nil {
+		return nil, err
+	}
+	uc.indexProject(*project)
+	uc.repo.LogEvent(entity.NewProjectEvent(entity.Create, *project))
+	for _, item := range goods {
+		uc.indexItem(item)
+		uc.repo.LogEvent(entity.NewGoodEvent(entity.Create, item))
+	}
+	return &entity.ProjectClone{Project: *project, GoodsCount: len(goods)}, nil
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+// GetItemRevisions diffs every revision against the one before it.
+func (uc *usecase) GetItemRevisions(ctx context.Context, id int) ([]entity.GoodsRevision, error) {
+	revisions, err := uc.repo.GetItemRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var prev *entity.Goods
+	for i := range revisions {
+		revisions[i].Changes, err = entity.DiffGoods(prev, revisions[i].Goods)
+		if err != nil {
+			return nil, err
+		}
+		prev = &revisions[i].Goods
+	}
+	return revisions, nil
+}
+
+// RevertItem logs the revert as an ordinary partial update.
+func (uc *usecase) RevertItem(ctx context.Context, item *entity.Goods, revision int) error {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return err
+	}
+	prev, changes, err := uc.repo.RevertItem(ctx, item, revision)
+	if err != nil {
+		return err
+	}
+	if changes.Empty() {
+		return nil
+	}
+	uc.indexItem(*item)
+	uc.repo.LogEvent(entity.NewGoodPatchEvent(prev, *item, changes))
+	return nil
+}
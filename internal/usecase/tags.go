@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/paxaf/HezzlTest/internal/entity"
+)
+
+func (uc *usecase) GetTags(ctx context.Context) ([]entity.Tag, error) {
+	return uc.repo.GetTags(ctx)
+}
+
+func (uc *usecase) GetTag(ctx context.Context, id int) (*entity.Tag, error) {
+	return uc.repo.GetTag(ctx, id)
+}
+
+func (uc *usecase) CreateTag(ctx context.Context, tag *entity.Tag) error {
+	return uc.repo.CreateTag(ctx, tag)
+}
+
+// RenameTag and DeleteTag change the tag lists of goods, every such good
+// gets an update event with its new tags.
+func (uc *usecase) RenameTag(ctx context.Context, tag *entity.Tag) error {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return err
+	}
+	goods, err := uc.repo.RenameTag(ctx, tag)
+	if err != nil {
+		return err
+	}
+	uc.logGoodsUpdates(goods)
+	return nil
+}
+
+func (uc *usecase) DeleteTag(ctx context.Context, id int) error {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return err
+	}
+	goods, err := uc.repo.DeleteTag(ctx, id)
+	if err != nil {
+		return err
+	}
+	uc.logGoodsUpdates(goods)
+	return nil
+}
+
+func (uc *usecase) SetItemTags(ctx context.Context, id int, tags []string) (*entity.Goods, error) {
+	err := uc.repo.CleanCache()
+	if err != nil {
+		return nil, err
+	}
+	change, err := uc.repo.SetItemTags(ctx, id, entity.NormalizeTags(tags))
+	if err != nil {
+		return nil, err
+	}
+	uc.repo.LogEvent(entity.NewGoodUpdateEvent(change.Prev, change.Item))
+	return &change.Item, nil
+}
+
+func (uc *usecase) logGoodsUpdates(goods []entity.GoodsChange) {
+	for _, change := range goods {
+		uc.repo.LogEvent(entity.NewGoodUpdateEvent(change.Prev, change.Item))
+	}
+}
@@ -2,30 +2,62 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/paxaf/HezzlTest/internal/entity"
 	"github.com/paxaf/HezzlTest/internal/repository"
 )
 
 type usecase struct {
-	repo repository.Repository
+	repo    repository.Repository
+	imports chan importTask
 }
 
 type Usecase interface {
-	GetAllItems(ctx context.Context, key string) ([]entity.Goods, error)
+	GetAllItems(ctx context.Context, key string, filter entity.GoodsFilter, page entity.Page) (*entity.GoodsResponse, error)
+	ExportItems(ctx context.Context, filter entity.GoodsFilter, fn func(entity.Goods) error) error
 	GetItem(ctx context.Context, key string, goodsId int) (*entity.Goods, error)
-	GetItemsByProject(ctx context.Context, key string, projectId int) ([]entity.Goods, error)
-	GetItemsByName(ctx context.Context, key string, name string) ([]entity.Goods, error)
+	GetItemsByProject(ctx context.Context, key string, projectId int, includeRemoved bool, tag string, page entity.Page) (*entity.GoodsResponse, error)
+	GetItemsByName(ctx context.Context, key string, name string, includeRemoved bool, tag string, page entity.Page) (*entity.GoodsResponse, error)
+	SearchItems(ctx context.Context, key string, query string, includeRemoved bool, page entity.Page) (*entity.GoodsSearchResponse, error)
 	CreateItem(ctx context.Context, item *entity.Goods) error
+	CreateItems(ctx context.Context, items []entity.Goods) ([]entity.Goods, error)
 	UpdateItem(ctx context.Context, item *entity.Goods) error
+	PatchItem(ctx context.Context, item *entity.Goods, patch entity.GoodsPatch) error
+	GetItemRevisions(ctx context.Context, id int) ([]entity.GoodsRevision, error)
+	RevertItem(ctx context.Context, item *entity.Goods, revision int) error
 	DeleteItem(ctx context.Context, id int) error
-	DeleteProject(ctx context.Context, id int) error
+	UpdateItems(ctx context.Context, sel entity.GoodsSelector, patch entity.GoodsPatch) ([]entity.Goods, error)
+	DeleteItems(ctx context.Context, sel entity.GoodsSelector) ([]entity.Goods, error)
+	RestoreItem(ctx context.Context, id int) (*entity.Goods, error)
+	ReprioritizeItem(ctx context.Context, id int, priority int) ([]entity.Goods, error)
+	MoveItem(ctx context.Context, id int, projectId int, priority int) (*entity.MoveResult, error)
+	SetItemStatus(ctx context.Context, id int, status entity.GoodsStatus) (*entity.Goods, error)
+	SetItemWindow(ctx context.Context, id int, from, to *time.Time) (*entity.Goods, error)
+	ApplyGoodsWindows(ctx context.Context, now time.Time) (int, error)
+	SetItemTags(ctx context.Context, id int, tags []string) (*entity.Goods, error)
+	GetTags(ctx context.Context) ([]entity.Tag, error)
+	GetTag(ctx context.Context, id int) (*entity.Tag, error)
+	CreateTag(ctx context.Context, tag *entity.Tag) error
+	RenameTag(ctx context.Context, tag *entity.Tag) error
+	DeleteTag(ctx context.Context, id int) error
+	DeleteProject(ctx context.Context, id int, force bool) error
+	CloneProject(ctx context.Context, id int, name string) (*entity.ProjectClone, error)
 	AddProject(ctx context.Context, item *entity.Project) error
 	UpdateProject(ctx context.Context, item *entity.Project) error
-	GetProjects(ctx context.Context, key string) ([]entity.Project, error)
+	GetProjects(ctx context.Context, key string, filter entity.ProjectFilter, page entity.Page) (*entity.ProjectResponse, error)
+	SetProjectStatus(ctx context.Context, id int, status entity.ProjectStatus) (*entity.Project, error)
 	GetProject(ctx context.Context, key string, id int) (*entity.Project, error)
+	GetProjectSummary(ctx context.Context, id int) (*entity.ProjectSummary, error)
+	Autocomplete(ctx context.Context, scope entity.SuggestScope, query string, projectId int, limit int) ([]entity.Suggestion, error)
+	ImportItems(ctx context.Context, projectId int, items []entity.Goods, upsert bool) (*entity.ImportJob, error)
+	GetImportJob(ctx context.Context, id int) (*entity.ImportJob, error)
+	RunImports(ctx context.Context)
+	FailUnfinishedImports(ctx context.Context) error
+	GetAuditEvents(ctx context.Context, filter entity.AuditFilter, page entity.Page) (*entity.AuditResponse, error)
+	GetProjectGoodsAsOf(ctx context.Context, projectId int, asOf time.Time, includeRemoved bool) (*entity.ProjectGoodsAsOf, error)
 }
 
 func New(repo repository.Repository) *usecase {
-	return &usecase{repo: repo}
+	return &usecase{repo: repo, imports: make(chan importTask, importQueueSize)}
 }
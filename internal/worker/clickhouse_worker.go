@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
 	"github.com/paxaf/HezzlTest/internal/entity"
 	"github.com/paxaf/HezzlTest/internal/logger"
@@ -101,13 +102,20 @@ func (w *ClickHouseWorker) unmarshalEvent(data []byte) (*entity.Event, error) {
 		}
 		event.Payload = payload
 	case "good":
-		var payload entity.GoodEventPayload
-		if err := json.Unmarshal(data, &struct {
-			Payload *entity.GoodEventPayload `json:"payload"`
-		}{Payload: &payload}); err != nil {
+		var body struct {
+			Payload entity.GoodEventPayload  `json:"payload"`
+			Before  *entity.GoodEventPayload `json:"before"`
+			After   *entity.GoodEventPayload `json:"after"`
+		}
+		if err := json.Unmarshal(data, &body); err != nil {
 			return nil, err
 		}
-		event.Payload = payload
+		event.Payload = body.Payload
+		// before and after are kept for requeue, only the changed fields are
+		// stored
+		if body.Before != nil && body.After != nil {
+			event.Before, event.After = *body.Before, *body.After
+		}
 	default:
 		return nil, fmt.Errorf("unknown entity type: %s", base.Entity)
 	}
@@ -216,26 +224,102 @@ func (w *ClickHouseWorker) appendEventToBatch(batch driver.Batch, event *entity.
 			0,
 			false,
 			payload.CreatedAt,
+			nil,
+			nullableString(string(payload.Status)),
+			nullableString(string(payload.FromStatus)),
+			nil,
+			nil,
+			[]string{},
+			nil,
+			nil,
+			nil,
+			event.Timestamp,
+			[]string{},
+			uuid.New(),
 		)
 	case "good":
 		payload := event.Payload.(entity.GoodEventPayload)
+		var fromProject any
+		if event.FromProjectID != 0 {
+			fromProject = event.FromProjectID
+		}
+		tags := payload.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		attributes, err := attributesJSON(payload.Attributes)
+		if err != nil {
+			return err
+		}
+		changed := event.ChangedFields
+		if changed == nil {
+			changed = []string{}
+		}
 		return batch.Append(
 			event.Timestamp,
 			string(event.Action),
 			event.Entity,
 			event.EntityID,
 			event.ProjectID,
-			payload.Name,
+			nullable(payload.Name, ""),
 			payload.Description,
-			payload.Priority,
-			payload.Removed,
-			payload.CreatedAt,
+			nullable(payload.Priority, nil),
+			nullable(payload.Removed, nil),
+			nullable(payload.CreatedAt, nil),
+			fromProject,
+			goodsStatus(payload.Status),
+			goodsStatus(payload.FromStatus),
+			nullable(payload.ActiveFrom, nil),
+			nullable(payload.ActiveTo, nil),
+			tags,
+			attributes,
+			nullable(payload.Price, nil),
+			nullable(payload.Currency, nil),
+			event.Timestamp,
+			changed,
+			uuid.New(),
 		)
 	default:
 		return fmt.Errorf("unknown entity type: %s", event.Entity)
 	}
 }
 
+// nullable unwraps optional payload fields, the driver does not accept
+// pointers to int or bool for Nullable columns.
+func nullable[T any](v *T, null any) any {
+	if v == nil {
+		return null
+	}
+	return *v
+}
+
+func nullableString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func goodsStatus(s *entity.GoodsStatus) any {
+	if s == nil {
+		return nil
+	}
+	return nullableString(string(*s))
+}
+
+// attributesJSON keeps the attributes as JSON text, ClickHouse has no type for
+// arbitrary nested values.
+func attributesJSON(attrs map[string]interface{}) (any, error) {
+	if attrs == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed marshal attributes: %w", err)
+	}
+	return string(data), nil
+}
+
 func (w *ClickHouseWorker) ackMessages(items []EventWithAck) {
 	for _, item := range items {
 		if err := item.Msg.Ack(); err != nil {
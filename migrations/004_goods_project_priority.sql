@@ -0,0 +1,13 @@
+-- +goose Up
+UPDATE goods g
+SET priority = r.rn
+FROM (
+	SELECT id, project_id, ROW_NUMBER() OVER (PARTITION BY project_id ORDER BY priority, id) AS rn
+	FROM goods
+) r
+WHERE g.id = r.id AND g.project_id = r.project_id;
+
+CREATE INDEX IF NOT EXISTS idx_goods_project_priority ON goods(project_id, priority);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_goods_project_priority;
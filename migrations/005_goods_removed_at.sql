@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS removed_at TIMESTAMP;
+
+UPDATE goods SET removed_at = NOW() WHERE removed AND removed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_goods_active ON goods(project_id, priority) WHERE NOT removed;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_goods_active;
+ALTER TABLE goods DROP COLUMN IF EXISTS removed_at;
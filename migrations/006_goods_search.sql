@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('russian', name), 'A') ||
+	setweight(to_tsvector('english', name), 'A') ||
+	setweight(to_tsvector('russian', COALESCE(description, '')), 'B') ||
+	setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_goods_search ON goods USING GIN(search_vector);
+CREATE INDEX IF NOT EXISTS idx_goods_name_trgm ON goods USING GIN(name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_goods_description_trgm ON goods USING GIN(description gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_goods_description_trgm;
+DROP INDEX IF EXISTS idx_goods_name_trgm;
+DROP INDEX IF EXISTS idx_goods_search;
+ALTER TABLE goods DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS import_jobs(
+	id SERIAL PRIMARY KEY,
+	project_id INT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+	status TEXT NOT NULL,
+	upsert BOOLEAN NOT NULL DEFAULT false,
+	total INT NOT NULL,
+	processed INT NOT NULL DEFAULT 0,
+	created INT NOT NULL DEFAULT 0,
+	updated INT NOT NULL DEFAULT 0,
+	error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	finished_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS import_jobs;
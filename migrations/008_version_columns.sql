@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS version;
+ALTER TABLE goods DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE goods DROP COLUMN IF EXISTS version;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active'
+	CHECK (status IN ('draft', 'active', 'paused', 'archived'));
+ALTER TABLE projects ALTER COLUMN status SET DEFAULT 'draft';
+CREATE INDEX IF NOT EXISTS idx_projects_status ON projects(status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_projects_status;
+ALTER TABLE projects DROP COLUMN IF EXISTS status;
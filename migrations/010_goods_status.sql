@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active'
+	CHECK (status IN ('draft', 'active', 'paused', 'expired'));
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS active_from TIMESTAMP;
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS active_to TIMESTAMP;
+ALTER TABLE goods ADD CONSTRAINT goods_active_window
+	CHECK (active_from IS NULL OR active_to IS NULL OR active_from < active_to);
+
+CREATE INDEX IF NOT EXISTS idx_goods_active_from ON goods(active_from) WHERE status = 'draft';
+CREATE INDEX IF NOT EXISTS idx_goods_active_to ON goods(active_to) WHERE status <> 'expired';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_goods_active_to;
+DROP INDEX IF EXISTS idx_goods_active_from;
+ALTER TABLE goods DROP CONSTRAINT IF EXISTS goods_active_window;
+ALTER TABLE goods DROP COLUMN IF EXISTS active_to;
+ALTER TABLE goods DROP COLUMN IF EXISTS active_from;
+ALTER TABLE goods DROP COLUMN IF EXISTS status;
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE goods ADD CONSTRAINT goods_id_key UNIQUE (id);
+
+CREATE TABLE IF NOT EXISTS tags(
+	id SERIAL PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS goods_tags(
+	goods_id INT NOT NULL REFERENCES goods(id) ON DELETE CASCADE,
+	tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY(goods_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_goods_tags_tag_id ON goods_tags(tag_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_goods_tags_tag_id;
+DROP TABLE IF EXISTS goods_tags;
+DROP TABLE IF EXISTS tags;
+ALTER TABLE goods DROP CONSTRAINT IF EXISTS goods_id_key;
@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_goods_attributes ON goods USING GIN(attributes jsonb_path_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_goods_attributes;
+ALTER TABLE goods DROP COLUMN IF EXISTS attributes;
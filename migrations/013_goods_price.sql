@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS price NUMERIC(15, 4) CHECK (price >= 0);
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS currency CHAR(3) CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE goods ADD CONSTRAINT goods_price_currency
+	CHECK ((price IS NULL) = (currency IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_goods_project_price ON goods(project_id, currency, price) WHERE price IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_goods_project_price;
+ALTER TABLE goods DROP CONSTRAINT IF EXISTS goods_price_currency;
+ALTER TABLE goods DROP COLUMN IF EXISTS currency;
+ALTER TABLE goods DROP COLUMN IF EXISTS price;
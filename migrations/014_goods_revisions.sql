@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS goods_revisions(
+	goods_id INT NOT NULL REFERENCES goods(id) ON DELETE CASCADE,
+	revision INT NOT NULL,
+	action TEXT NOT NULL,
+	reverted_from INT,
+	data JSONB NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	PRIMARY KEY(goods_id, revision)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS goods_revisions;
@@ -0,0 +1 @@
+ALTER TABLE logs.events ADD COLUMN IF NOT EXISTS from_project_id Nullable(Int32);
@@ -0,0 +1,2 @@
+ALTER TABLE logs.events ADD COLUMN IF NOT EXISTS status Nullable(String);
+ALTER TABLE logs.events ADD COLUMN IF NOT EXISTS from_status Nullable(String);
@@ -0,0 +1,2 @@
+ALTER TABLE logs.events ADD COLUMN IF NOT EXISTS active_from Nullable(DateTime);
+ALTER TABLE logs.events ADD COLUMN IF NOT EXISTS active_to Nullable(DateTime);
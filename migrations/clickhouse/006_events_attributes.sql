@@ -0,0 +1 @@
+ALTER TABLE logs.events ADD COLUMN IF NOT EXISTS attributes Nullable(String);
@@ -0,0 +1,2 @@
+ALTER TABLE logs.events ADD COLUMN IF NOT EXISTS price Nullable(Decimal(18, 4));
+ALTER TABLE logs.events ADD COLUMN IF NOT EXISTS currency Nullable(String);
@@ -0,0 +1 @@
+ALTER TABLE logs.events ADD COLUMN IF NOT EXISTS event_time DateTime64(6) DEFAULT timestamp;
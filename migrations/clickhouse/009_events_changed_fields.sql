@@ -0,0 +1,2 @@
+ALTER TABLE logs.events ADD COLUMN IF NOT EXISTS changed_fields Array(String) DEFAULT [];
+ALTER TABLE logs.events ADD INDEX IF NOT EXISTS idx_changed_fields changed_fields TYPE bloom_filter GRANULARITY 4;
@@ -0,0 +1,2 @@
+ALTER TABLE logs.events ADD COLUMN IF NOT EXISTS event_id UUID DEFAULT generateUUIDv4();
+ALTER TABLE logs.events MATERIALIZE COLUMN event_id;